@@ -0,0 +1,179 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Args are the arguments of a parser tag, e.g. `parser:"timestamp(tz=Asia/Shanghai)"` or
+// `parser:"array_to_string(sep=;)"`. They are parsed once when the field config is built.
+type Args map[string]string
+
+// Get returns the argument of name, or defaultValue if it is not set.
+func (a Args) Get(name, defaultValue string) string {
+	if v, ok := a[name]; ok {
+		return v
+	}
+
+	return defaultValue
+}
+
+type (
+	// ArgsFieldParser is a field parser receiving the arguments of the parser tag.
+	ArgsFieldParser func(dest reflect.Value, val any, args Args) error
+
+	// ArgsFieldEncoder is a field encoder receiving the arguments of the parser tag.
+	ArgsFieldEncoder func(src reflect.Value, args Args) (any, error)
+
+	// ArgsValidator checks the arguments of a parser when the field config is built.
+	ArgsValidator func(args Args) error
+)
+
+// SetArgsFieldParser registers a parser receiving tag arguments into the default parser.
+func SetArgsFieldParser(name string, valueParser ValueParser, fieldParser ArgsFieldParser) {
+	defaultParser.SetArgsFieldParser(name, valueParser, fieldParser)
+}
+
+// SetArgsFieldEncoder registers an encoder receiving tag arguments into the default parser.
+func SetArgsFieldEncoder(name string, encoder ArgsFieldEncoder) {
+	defaultParser.SetArgsFieldEncoder(name, encoder)
+}
+
+// SetParserArgs sets the default arguments of the parser of name in the default parser.
+func SetParserArgs(name string, args Args) {
+	defaultParser.SetParserArgs(name, args)
+}
+
+// SetArgsValidator sets the validator of the arguments of the parser of name in the default parser.
+func SetArgsValidator(name string, validator ArgsValidator) {
+	defaultParser.SetArgsValidator(name, validator)
+}
+
+func withoutArgsParser(fieldParser FieldParser) ArgsFieldParser {
+	return func(dest reflect.Value, val any, _ Args) error {
+		return fieldParser(dest, val)
+	}
+}
+
+func withoutArgsEncoder(encoder FieldEncoder) ArgsFieldEncoder {
+	return func(src reflect.Value, _ Args) (any, error) {
+		return encoder(src)
+	}
+}
+
+// parseParserTag splits a parser tag like `name(k1=v1,k2=v2)` into the name and the arguments,
+// an argument without a value, e.g. `name(flag)`, is set to an empty string.
+// A value quoted by ' or " may contain commas and parentheses, e.g. `array_to_string(sep=', ')`.
+func parseParserTag(tag string) (string, Args, error) {
+	name, argStr, hasArgs := strings.Cut(tag, "(")
+	name = strings.TrimSpace(name)
+	if !hasArgs {
+		return name, nil, nil
+	}
+
+	argStr, closed := strings.CutSuffix(strings.TrimSpace(argStr), ")")
+	if !closed {
+		return "", nil, fmt.Errorf("invalid parser tag %s", tag)
+	}
+
+	args, err := parseArgs(argStr)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid parser tag %s: %v", tag, err)
+	}
+
+	return name, args, nil
+}
+
+var argNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseArgs parses the arguments of a parser tag, rejecting empty arguments and empty values,
+// which are the remains of a comma or parenthesis in an unquoted value.
+func parseArgs(s string) (Args, error) {
+	args := Args{}
+	if strings.TrimSpace(s) == "" {
+		return args, nil
+	}
+
+	for {
+		end := strings.IndexAny(s, ",=")
+		if end < 0 {
+			end = len(s)
+		}
+
+		key := strings.TrimSpace(s[:end])
+		if key == "" {
+			return nil, fmt.Errorf("empty argument")
+		}
+		if !argNamePattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid argument %s, quote a value with a comma, e.g. format='Jan 2, 2006'", key)
+		}
+
+		if end == len(s) || s[end] == ',' {
+			args[key] = ""
+			if end == len(s) {
+				return args, nil
+			}
+			s = s[end+1:]
+			continue
+		}
+
+		val, rest, more, err := cutArgValue(s[end+1:])
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %v", key, err)
+		}
+		args[key] = val
+
+		if !more {
+			return args, nil
+		}
+		s = rest
+	}
+}
+
+// cutArgValue cuts the value of an argument from s, returning the arguments after its comma,
+// more reports whether there is a comma.
+func cutArgValue(s string) (val, rest string, more bool, err error) {
+	trimmed := strings.TrimSpace(s)
+	if trimmed != "" && (trimmed[0] == '\'' || trimmed[0] == '"') {
+		end := strings.IndexByte(trimmed[1:], trimmed[0])
+		if end < 0 {
+			return "", "", false, fmt.Errorf("unclosed quote")
+		}
+
+		val, rest = trimmed[1:end+1], strings.TrimSpace(trimmed[end+2:])
+		if rest == "" {
+			return val, "", false, nil
+		}
+		if rest[0] != ',' {
+			return "", "", false, fmt.Errorf("invalid text %s after quoted value", rest)
+		}
+
+		return val, rest[1:], true, nil
+	}
+
+	val, rest, more = strings.Cut(s, ",")
+	if val == "" {
+		return "", "", false, fmt.Errorf("empty value, quote a value with a comma, e.g. sep=', '")
+	}
+
+	return val, rest, more, nil
+}
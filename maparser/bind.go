@@ -0,0 +1,100 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import "fmt"
+
+type (
+	// ParserBinder builds the parsers of a name from the registry of p, for parsers depending on
+	// other registered parsers or on attributes of p. WithParsersFrom binds them to the new parser.
+	ParserBinder func(p *Parser) (ValueParser, ArgsFieldParser)
+
+	// EncoderBinder builds the encoder of a name from the registry of p, see ParserBinder.
+	EncoderBinder func(p *Parser) ArgsFieldEncoder
+
+	// ValidatorBinder builds the args validator of a name from the registry of p, see ParserBinder.
+	ValidatorBinder func(p *Parser) ArgsValidator
+)
+
+// SetBoundFieldParser registers the parsers built by bind for p.
+func (p *Parser) SetBoundFieldParser(name string, bind ParserBinder) {
+	valueParser, fieldParser := bind(p)
+	p.SetArgsFieldParser(name, valueParser, fieldParser)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.parserBinders[name] = bind
+}
+
+// SetBoundFieldEncoder registers the encoder built by bind for p.
+func (p *Parser) SetBoundFieldEncoder(name string, bind EncoderBinder) {
+	p.SetArgsFieldEncoder(name, bind(p))
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.encoderBinders[name] = bind
+}
+
+// SetBoundArgsValidator sets the args validator built by bind for p.
+func (p *Parser) SetBoundArgsValidator(name string, bind ValidatorBinder) {
+	p.SetArgsValidator(name, bind(p))
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.validatorBinders[name] = bind
+}
+
+// ValidateArgs checks args by the validator of the parser of name, merged with its default arguments
+// as the parser of FieldParser receives them. It fails if no parser of name is registered.
+func (p *Parser) ValidateArgs(name string, args Args) error {
+	if _, _, ok := p.getFieldParser(name); !ok {
+		return fmt.Errorf("invalid parser %s", name)
+	}
+
+	validator, ok := p.getArgsValidator(name)
+	if !ok {
+		return nil
+	}
+
+	if err := validator(p.mergeParserArgs(name, args)); err != nil {
+		return fmt.Errorf("parser %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// SetAttr sets an attribute of p, e.g. the registry of a package built on top of the parsers.
+// Attributes are copied by WithParsersFrom.
+func (p *Parser) SetAttr(key, val any) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.attrs[key] = val
+}
+
+// Attr returns the attribute of key.
+func (p *Parser) Attr(key any) (any, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	val, ok := p.attrs[key]
+	return val, ok
+}
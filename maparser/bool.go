@@ -0,0 +1,153 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// BoolTarget is the target of the bool parser.
+var BoolTarget = FieldTarget{Kinds: []reflect.Kind{reflect.Bool}}
+
+// the strings parsed as true and false, compared case insensitively, unless the `true` and `false`
+// arguments are given, e.g. by SetParserArgs("bool", Args{"true": "通过", "false": "驳回"}).
+var (
+	trueStrings  = []string{"true", "yes", "y", "on", "1", "是", "✓", "✔", "√"}
+	falseStrings = []string{"false", "no", "n", "off", "0", "否", "✗", "✘", "×"}
+)
+
+// boolStrings returns the truthy or falsy strings of the `true` or `false` argument,
+// separated by `|`, e.g. `parser:"bool(true=是|对,false=否|错)"`.
+func boolStrings(args Args, name string, defaults []string) []string {
+	s, ok := args[name]
+	if !ok {
+		return defaults
+	}
+
+	return strings.Split(s, "|")
+}
+
+func containsFold(arr []string, s string) bool {
+	for _, v := range arr {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ToBool converts a json bool, a 0 or 1 number, or a truthy or falsy string of args to bool,
+// taking the first element of an array. An absent value or an empty string returns false for ok.
+func ToBool(val any, args Args) (b, ok bool, err error) {
+	if arr, isArr := val.([]any); isArr {
+		if len(arr) == 0 {
+			return false, false, nil
+		}
+		val = arr[0]
+	}
+
+	if val == nil {
+		return false, false, nil
+	}
+
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), true, nil
+	case reflect.String:
+		s := strings.TrimSpace(v.String())
+		switch {
+		case s == "":
+			return false, false, nil
+		case containsFold(boolStrings(args, "true", trueStrings), s):
+			return true, true, nil
+		case containsFold(boolStrings(args, "false", falseStrings), s):
+			return false, true, nil
+		default:
+			return false, false, fmt.Errorf("invalid bool %s", s)
+		}
+	}
+
+	n, err := toNumber(val)
+	if err != nil {
+		return false, false, fmt.Errorf("invalid bool type %T", val)
+	}
+
+	switch n.float64() {
+	case 0:
+		return false, true, nil
+	case 1:
+		return true, true, nil
+	default:
+		return false, false, fmt.Errorf("invalid bool %v", val)
+	}
+}
+
+func BoolValueParser(val any) (any, error) {
+	b, ok, err := ToBool(val, nil)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func BoolFieldParser(dest reflect.Value, val any) error {
+	return BoolArgsFieldParser(dest, val, nil)
+}
+
+// BoolArgsFieldParser sets a bool field, strings are matched against the `true` and `false`
+// arguments if given, otherwise against the default strings.
+func BoolArgsFieldParser(dest reflect.Value, val any, args Args) error {
+	b, ok, err := ToBool(val, args)
+	if err != nil || !ok {
+		return err
+	}
+
+	AllocIndirect(dest).SetBool(b)
+
+	return nil
+}
+
+// BoolArgsFieldEncoder encodes a bool field as the json bool of a checkbox field,
+// or as the first string of the `true` or `false` argument for a text field.
+func BoolArgsFieldEncoder(src reflect.Value, args Args) (any, error) {
+	v, ok := indirectValue(src)
+	if !ok {
+		return nil, nil
+	}
+
+	if v.Kind() != reflect.Bool {
+		return nil, fmt.Errorf("BoolFieldEncoder: invalid type %s", v.Type())
+	}
+
+	name := "false"
+	if v.Bool() {
+		name = "true"
+	}
+
+	if s, textOk := args[name]; textOk {
+		first, _, _ := strings.Cut(s, "|")
+		return first, nil
+	}
+
+	return v.Bool(), nil
+}
@@ -0,0 +1,113 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToBool(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     any
+		args    Args
+		want    bool
+		wantOk  bool
+		wantErr bool
+	}{
+		{name: "nil"},
+		{name: "empty string", val: " "},
+		{name: "empty array", val: []any{}},
+		{name: "true", val: true, want: true, wantOk: true},
+		{name: "false", val: false, wantOk: true},
+		{name: "array", val: []any{true}, want: true, wantOk: true},
+		{name: "one", val: float64(1), want: true, wantOk: true},
+		{name: "zero", val: 0, wantOk: true},
+		{name: "uint one", val: uint8(1), want: true, wantOk: true},
+		{name: "json number", val: json.Number("1"), want: true, wantOk: true},
+		{name: "two", val: 2, wantErr: true},
+		{name: "string true", val: "TRUE", want: true, wantOk: true},
+		{name: "string yes", val: " Yes ", want: true, wantOk: true},
+		{name: "string no", val: "no", wantOk: true},
+		{name: "string 是", val: "是", want: true, wantOk: true},
+		{name: "string 否", val: "否", wantOk: true},
+		{name: "string ✓", val: "✓", want: true, wantOk: true},
+		{name: "string 0", val: "0", wantOk: true},
+		{name: "unknown string", val: "maybe", wantErr: true},
+		{name: "args true", val: "对", args: Args{"true": "对|正确", "false": "错"}, want: true, wantOk: true},
+		{name: "args false", val: "错", args: Args{"true": "对|正确", "false": "错"}, wantOk: true},
+		{name: "args replace defaults", val: "yes", args: Args{"true": "对", "false": "错"}, wantErr: true},
+		{name: "invalid type", val: map[string]any{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := ToBool(tt.val, tt.args)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type BoolObj struct {
+	Done     bool  `json:"done" key:"完成"`
+	Checked  *bool `json:"checked" key:"勾选" parser:"checkbox"`
+	Approved bool  `json:"approved" key:"审批" parser:"bool(true=通过,false=驳回|退回)"`
+	Missing  *bool `json:"missing" key:"缺失"`
+}
+
+func TestParseBool(t *testing.T) {
+	obj := &BoolObj{}
+	err := Parse(obj, map[string]any{
+		"完成": "是",
+		"勾选": false,
+		"审批": "退回",
+	})
+	assert.Nil(t, err)
+	assert.True(t, obj.Done)
+	assert.NotNil(t, obj.Checked)
+	assert.False(t, *obj.Checked)
+	assert.False(t, obj.Approved)
+	assert.Nil(t, obj.Missing)
+
+	m, err := Encode(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"完成": true, "勾选": false, "审批": "驳回"}, m)
+
+	assert.NotNil(t, Parse(&BoolObj{}, map[string]any{"审批": "是"}))
+
+	v, err := ParseValue("checkbox", true)
+	assert.Nil(t, err)
+	assert.Equal(t, true, v)
+
+	// the strings of every bool field of a parser are set by its default arguments.
+	p := New(WithParserArgs("bool", Args{"true": "通过", "false": "驳回"}))
+	obj = &BoolObj{}
+	assert.Nil(t, p.Parse(obj, map[string]any{"完成": "通过"}))
+	assert.True(t, obj.Done)
+	assert.NotNil(t, p.Parse(&BoolObj{}, map[string]any{"完成": "是"}))
+}
@@ -0,0 +1,360 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// fieldConfig is the parse config of a tagged struct field,
+// the configs of a type are kept in field declaration order.
+//
+// Fields of anonymous embedded structs, and of nested structs tagged with `prefix`,
+// are flattened into the configs of the outer type, with Index as the path to the field.
+type fieldConfig struct {
+	Name  string
+	Index []int
+	Type  reflect.Type
+
+	// Key is the primary key used for encoding, Keys are the alternatives looked up in order
+	// when parsing, set by `key:"员工姓名|姓名"` for renamed columns.
+	Key        string
+	Keys       []string
+	FoldedKeys []string
+
+	ParserName string
+	Args       Args
+	Parser     ArgsFieldParser
+	Encoder    ArgsFieldEncoder
+
+	// Nested marks a struct field parsed from a nested map value.
+	Nested bool
+
+	// Required fails the parsing if the value is absent or empty, set by `key:"姓名,required"`.
+	Required bool
+
+	// OmitEmpty leaves a zero field out of the encoded map, set by `key:"姓名,omitempty"`.
+	OmitEmpty bool
+
+	// ReadOnly leaves the field out of the encoded map, set by `key:"项目群,readonly"` on the other
+	// fields parsed from the key of a written field.
+	ReadOnly bool
+
+	// Default is parsed in place of an absent or empty value, set by the `default` tag.
+	Default    string
+	HasDefault bool
+}
+
+// parseKeyTag splits a key tag into the alternative keys and the options.
+func parseKeyTag(c *fieldConfig, tag, keyPrefix string) error {
+	keys, options, _ := strings.Cut(tag, ",")
+	for _, key := range strings.Split(keys, "|") {
+		c.Keys = append(c.Keys, keyPrefix+strings.TrimSpace(key))
+		c.FoldedKeys = append(c.FoldedKeys, foldKey(keyPrefix+key))
+	}
+	c.Key = c.Keys[0]
+
+	for _, option := range strings.Split(options, ",") {
+		switch strings.TrimSpace(option) {
+		case "":
+		case "required":
+			c.Required = true
+		case "omitempty":
+			c.OmitEmpty = true
+		case "readonly":
+			c.ReadOnly = true
+		default:
+			return fmt.Errorf("invalid key option %s", option)
+		}
+	}
+
+	return nil
+}
+
+// isEmptyValue reports whether a record value is absent or empty.
+func isEmptyValue(val any) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+func (p *Parser) getTypeFieldConfigs(t reflect.Type) ([]fieldConfig, error) {
+	if c, ok := p.typeConfigs.Load(t); ok {
+		return c.([]fieldConfig), nil
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid type %s, struct required", t)
+	}
+
+	for {
+		p.lock.RLock()
+		generation := p.generation
+		p.lock.RUnlock()
+
+		c, err := p.buildFieldConfigs(t, nil, "", "", map[reflect.Type]bool{})
+		if err != nil {
+			return nil, err
+		}
+
+		// a registration while building may have read parts of the old registry, build again.
+		// The read lock keeps registrations out between the check and the store.
+		p.lock.RLock()
+		if p.generation != generation {
+			p.lock.RUnlock()
+			continue
+		}
+		actual, _ := p.typeConfigs.LoadOrStore(t, c)
+		p.lock.RUnlock()
+
+		return actual.([]fieldConfig), nil
+	}
+}
+
+// clearTypeConfigs drops the cached configs after a registration, called with p.lock held.
+func (p *Parser) clearTypeConfigs() {
+	p.generation++
+	p.typeConfigs.Clear()
+}
+
+func (p *Parser) buildFieldConfigs(t reflect.Type, index []int, namePrefix, keyPrefix string,
+	visiting map[reflect.Type]bool,
+) ([]fieldConfig, error) {
+	if visiting[t] {
+		return nil, fmt.Errorf("recursive flattened struct %s", t)
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var c []fieldConfig
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
+
+		key, tagOk := field.Tag.Lookup("key")
+		if !tagOk {
+			prefix, prefixOk := field.Tag.Lookup("prefix")
+			structType, isStruct := structTypeOf(field.Type)
+			if !isStruct || (!prefixOk && !field.Anonymous) {
+				continue
+			}
+
+			flattened, err := p.buildFieldConfigs(structType, fieldIndex, namePrefix+field.Name+".", keyPrefix+prefix, visiting)
+			if err != nil {
+				return nil, err
+			}
+
+			c = append(c, flattened...)
+			continue
+		}
+
+		config := fieldConfig{
+			Name:  namePrefix + field.Name,
+			Index: fieldIndex,
+			Type:  field.Type,
+		}
+
+		if err := parseKeyTag(&config, key, keyPrefix); err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", t, field.Name, err)
+		}
+		config.Default, config.HasDefault = field.Tag.Lookup("default")
+
+		parserTag, parserOk := field.Tag.Lookup("parser")
+		if !parserOk && implementsPtr(field.Type, fieldUnmarshalerType) {
+			config.ParserName = unmarshalerParserName
+			config.Parser = unmarshalerFieldParser
+			if implementsPtr(field.Type, fieldMarshalerType) {
+				config.Encoder = marshalerFieldEncoder
+			}
+			if err := checkDefault(config); err != nil {
+				return nil, fmt.Errorf("%s.%s: %v", t, field.Name, err)
+			}
+			c = append(c, config)
+			continue
+		}
+
+		if !parserOk {
+			inferred, inferOk := p.inferParserName(field.Type)
+			if !inferOk {
+				if structType, isStruct := structTypeOf(field.Type); isStruct && hasKeyFields(structType, map[reflect.Type]bool{}) {
+					if config.HasDefault {
+						return nil, fmt.Errorf("%s.%s: default of nested struct not supported", t, field.Name)
+					}
+					config.Nested = true
+					c = append(c, config)
+					continue
+				}
+
+				return nil, fmt.Errorf("%s.%s: no parser inferred for type %s", t, field.Name, field.Type)
+			}
+
+			parserTag = inferred
+		}
+
+		parserName, args, err := parseParserTag(parserTag)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", t, field.Name, err)
+		}
+		args = p.mergeParserArgs(parserName, args)
+
+		parser, encoder, parserOk := p.getFieldParser(parserName)
+		if !parserOk {
+			return nil, fmt.Errorf("%s.%s: invalid parser %s", t, field.Name, parserName)
+		}
+
+		if validator, validatorOk := p.getArgsValidator(parserName); validatorOk {
+			if err = validator(args); err != nil {
+				return nil, fmt.Errorf("%s.%s: parser %s: %v", t, field.Name, parserName, err)
+			}
+		}
+
+		if target, targetOk := p.getFieldTarget(parserName); targetOk && !target.Accepts(field.Type) {
+			return nil, &FieldTypeError{
+				Struct:   t,
+				Field:    field.Name,
+				Parser:   parserName,
+				Type:     field.Type,
+				Expected: target,
+			}
+		}
+
+		config.ParserName = parserName
+		config.Args = args
+		config.Parser = parser
+		config.Encoder = encoder
+		if err := checkDefault(config); err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", t, field.Name, err)
+		}
+		c = append(c, config)
+	}
+
+	return c, nil
+}
+
+// checkDefault parses the default of a field into a zero value of the field type,
+// so that a default the parser rejects fails building the config instead of every record.
+func checkDefault(config fieldConfig) error {
+	if !config.HasDefault {
+		return nil
+	}
+
+	if err := callFieldParser(config.Parser, reflect.New(config.Type).Elem(), config.Default, config.Args); err != nil {
+		return fmt.Errorf("invalid default %q: %v", config.Default, err)
+	}
+
+	return nil
+}
+
+// inferParserName returns the parser of a field without parser tag from its type,
+// the parser set for the type takes precedence over the one of its kind.
+func (p *Parser) inferParserName(t reflect.Type) (string, bool) {
+	if name, ok := p.getTypeParser(t); ok {
+		return name, true
+	}
+
+	switch {
+	case StringTarget.Accepts(t):
+		return "string", true
+	case IntTarget.Accepts(t):
+		return "int", true
+	case FloatTarget.Accepts(t):
+		return "float", true
+	case BoolTarget.Accepts(t):
+		return "bool", true
+	default:
+		return "", false
+	}
+}
+
+// indirectType returns the type t points to.
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// structTypeOf returns the struct type of a struct or pointer to struct type.
+func structTypeOf(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t, t.Kind() == reflect.Struct
+}
+
+// hasKeyFields reports whether the struct type t has fields to parse,
+// which tells a nested struct apart from struct values like time.Time.
+func hasKeyFields(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup("key"); ok {
+			return true
+		}
+
+		if _, ok := field.Tag.Lookup("prefix"); ok || field.Anonymous {
+			if structType, isStruct := structTypeOf(field.Type); isStruct && hasKeyFields(structType, visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// fieldByIndex returns the nested field of v by index. Nil struct pointers on the way are
+// allocated if alloc is true, otherwise false is returned.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+// foldKey normalizes a key for case and whitespace insensitive matching.
+func foldKey(key string) string {
+	return strings.ToLower(strings.Join(strings.Fields(key), ""))
+}
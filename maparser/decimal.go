@@ -0,0 +1,479 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxDecimalScale is the largest scale of a Decimal, which keeps 10^scale in int64.
+const MaxDecimalScale = 18
+
+// Decimal is a fixed-point number of Unscaled * 10^-Scale, which holds money values
+// exactly where float64 does not, e.g. Decimal{Unscaled: 123450, Scale: 2} is 1234.50.
+type Decimal struct {
+	Unscaled int64
+	Scale    int
+}
+
+// DecimalType is the reflect type of Decimal.
+var DecimalType = reflect.TypeOf(Decimal{})
+
+// DecimalTarget is the target of the decimal and currency parsers: a Decimal, an integer of
+// minor units, or a string keeping the exact text.
+var DecimalTarget = FieldTarget{
+	Kinds: append(append([]reflect.Kind{}, IntTarget.Kinds...), reflect.String),
+	Types: []reflect.Type{DecimalType},
+}
+
+var pow10 = func() [MaxDecimalScale + 1]int64 {
+	var p [MaxDecimalScale + 1]int64
+	p[0] = 1
+	for i := 1; i <= MaxDecimalScale; i++ {
+		p[i] = p[i-1] * 10
+	}
+	return p
+}()
+
+// NewDecimal returns the decimal unscaled * 10^-scale, a negative scale multiplies unscaled
+// by a power of ten, e.g. NewDecimal(5, -1) is 50.
+func NewDecimal(unscaled int64, scale int) Decimal {
+	return Decimal{Unscaled: unscaled, Scale: scale}
+}
+
+// ParseDecimal parses a numeric string, which may have thousand separators, a % suffix,
+// a currency symbol like ¥ or $, or a currency code like CNY, e.g. "¥1,234.50" or "-12.5%".
+func ParseDecimal(s string) (Decimal, error) {
+	text := trimCurrency(s)
+	if text == "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	scale := 0
+	if pct, ok := strings.CutSuffix(text, "%"); ok {
+		text = strings.TrimSpace(pct)
+		scale = 2
+		if text == "" {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+	}
+
+	if thousandsPattern.MatchString(text) {
+		text = strings.ReplaceAll(text, ",", "")
+	}
+
+	sign := ""
+	if text[0] == '+' || text[0] == '-' {
+		sign, text = text[:1], text[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(text, ".")
+	if (intPart == "" && fracPart == "") || !isDigits(intPart) || !isDigits(fracPart) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	scale += len(fracPart)
+	if scale > MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("decimal %q exceeds scale %d", s, MaxDecimalScale)
+	}
+
+	digits := strings.TrimLeft(intPart+fracPart, "0")
+	if digits == "" {
+		return Decimal{Scale: scale}, nil
+	}
+
+	unscaled, err := strconv.ParseInt(sign+digits, 10, 64)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("decimal %q overflows int64", s)
+	}
+
+	return Decimal{Unscaled: unscaled, Scale: scale}, nil
+}
+
+// trimCurrency removes spaces, currency symbols and a leading or trailing currency code,
+// moving a sign after the currency symbol to the front, e.g. "¥-1.5" to "-1.5".
+func trimCurrency(s string) string {
+	s = strings.TrimSpace(s)
+
+	sign := ""
+	for {
+		trimmed := strings.TrimFunc(s, func(r rune) bool {
+			return unicode.IsSpace(r) || unicode.Is(unicode.Sc, r) || r == '元'
+		})
+		trimmed = trimCurrencyCode(trimmed)
+
+		if sign == "" && trimmed != "" && (trimmed[0] == '-' || trimmed[0] == '+') &&
+			len(trimmed) > 1 && !isDigit(trimmed[1]) && trimmed[1] != '.' {
+			sign, trimmed = trimmed[:1], trimmed[1:]
+		}
+
+		if trimmed == s {
+			return sign + s
+		}
+		s = trimmed
+	}
+}
+
+// trimCurrencyCode removes a three letter currency code like CNY or USD.
+func trimCurrencyCode(s string) string {
+	isCode := func(code string) bool {
+		for i := 0; i < len(code); i++ {
+			if code[i] < 'A' || code[i] > 'Z' {
+				return false
+			}
+		}
+		return true
+	}
+
+	if len(s) > 3 && isCode(s[:3]) {
+		return s[3:]
+	}
+
+	if len(s) > 3 && isCode(s[len(s)-3:]) {
+		return s[:len(s)-3]
+	}
+
+	return s
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DecimalFromFloat converts f by its shortest decimal representation, so that the float64
+// 0.1 of a currency field converts to exactly 0.1.
+func DecimalFromFloat(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("invalid decimal %v", f)
+	}
+
+	return ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// ToDecimal converts a record value of any int, uint or float kind, numeric or currency string,
+// json.Number or Decimal, taking the first element of an array. An absent value returns false.
+func ToDecimal(val any) (Decimal, bool, error) {
+	if arr, ok := val.([]any); ok {
+		if len(arr) == 0 {
+			return Decimal{}, false, nil
+		}
+		val = arr[0]
+	}
+
+	switch v := val.(type) {
+	case nil:
+		return Decimal{}, false, nil
+	case Decimal:
+		return v, true, nil
+	case *Decimal:
+		if v == nil {
+			return Decimal{}, false, nil
+		}
+		return *v, true, nil
+	}
+
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Decimal{Unscaled: v.Int()}, true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return Decimal{}, false, fmt.Errorf("decimal %d overflows int64", v.Uint())
+		}
+		return Decimal{Unscaled: int64(v.Uint())}, true, nil
+	case reflect.Float32:
+		// format by the float32 precision, float32(0.1) is not 0.1 as float64.
+		d, err := ParseDecimal(strconv.FormatFloat(v.Float(), 'f', -1, 32))
+		return d, err == nil, err
+	case reflect.Float64:
+		d, err := DecimalFromFloat(v.Float())
+		return d, err == nil, err
+	case reflect.String:
+		if strings.TrimSpace(v.String()) == "" {
+			return Decimal{}, false, nil
+		}
+		d, err := ParseDecimal(v.String())
+		return d, err == nil, err
+	default:
+		return Decimal{}, false, fmt.Errorf("invalid decimal type %T", val)
+	}
+}
+
+// Rescale returns d with the given scale, rounding by mode if digits are dropped.
+func (d Decimal) Rescale(scale int, mode RoundMode) (Decimal, error) {
+	if scale < 0 || scale > MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("invalid decimal scale %d", scale)
+	}
+
+	if d.Scale > MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("invalid decimal scale %d", d.Scale)
+	}
+
+	if scale >= d.Scale {
+		if d.Unscaled == 0 {
+			return Decimal{Scale: scale}, nil
+		}
+		if scale-d.Scale > MaxDecimalScale {
+			return Decimal{}, fmt.Errorf("decimal %s overflows scale %d", d, scale)
+		}
+
+		p := pow10[scale-d.Scale]
+		if d.Unscaled > math.MaxInt64/p || d.Unscaled < math.MinInt64/p {
+			return Decimal{}, fmt.Errorf("decimal %s overflows scale %d", d, scale)
+		}
+		return Decimal{Unscaled: d.Unscaled * p, Scale: scale}, nil
+	}
+
+	p := pow10[d.Scale-scale]
+	q, r := d.Unscaled/p, d.Unscaled%p
+	if r == 0 {
+		return Decimal{Unscaled: q, Scale: scale}, nil
+	}
+
+	sign := int64(1)
+	if r < 0 {
+		sign, r = -1, -r
+	}
+
+	switch mode {
+	case RoundError, "":
+		return Decimal{}, fmt.Errorf("decimal %s exceeds scale %d", d, scale)
+	case RoundTrunc:
+	case RoundFloor:
+		if sign < 0 {
+			q--
+		}
+	case RoundCeil:
+		if sign > 0 {
+			q++
+		}
+	case RoundHalfUp:
+		if r >= p-r {
+			q += sign
+		}
+	case RoundHalfEven:
+		if r > p-r || (r == p-r && q%2 != 0) {
+			q += sign
+		}
+	default:
+		return Decimal{}, fmt.Errorf("invalid round mode %s", mode)
+	}
+
+	return Decimal{Unscaled: q, Scale: scale}, nil
+}
+
+// String formats d with Scale fraction digits, e.g. "1234.50", or with -Scale trailing zeros
+// for a negative scale, e.g. "50" of NewDecimal(5, -1).
+func (d Decimal) String() string {
+	s := strconv.FormatInt(d.Unscaled, 10)
+	if d.Scale == 0 || d.Unscaled == 0 && d.Scale < 0 {
+		return s
+	}
+
+	if d.Scale < 0 {
+		return s + strings.Repeat("0", -d.Scale)
+	}
+
+	sign := ""
+	if s[0] == '-' {
+		sign, s = "-", s[1:]
+	}
+
+	if len(s) <= d.Scale {
+		s = strings.Repeat("0", d.Scale-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-d.Scale] + "." + s[len(s)-d.Scale:]
+}
+
+// Float64 returns the nearest float64 of d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// IsZero reports whether d is zero at any scale.
+func (d Decimal) IsZero() bool {
+	return d.Unscaled == 0
+}
+
+// MarshalJSON encodes d as a json number keeping all its digits.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes a json number or numeric string.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+
+	v, err := ParseDecimal(n.String())
+	if err != nil {
+		return err
+	}
+
+	*d = v
+	return nil
+}
+
+// argsScale returns the `scale` argument, or defaultScale if it is not set.
+func argsScale(args Args, defaultScale int) (int, bool, error) {
+	s, ok := args["scale"]
+	if !ok {
+		return defaultScale, defaultScale >= 0, nil
+	}
+
+	scale, err := strconv.Atoi(s)
+	if err != nil || scale < 0 || scale > MaxDecimalScale {
+		return 0, false, fmt.Errorf("invalid scale %s", s)
+	}
+
+	return scale, true, nil
+}
+
+// validateDecimalArgs checks the `scale` and `round` arguments of the decimal and currency parsers.
+func validateDecimalArgs(args Args) error {
+	if _, _, err := argsScale(args, -1); err != nil {
+		return err
+	}
+
+	return ValidateRoundArgs(args)
+}
+
+// DecimalValueParser converts a record value to a Decimal.
+func DecimalValueParser(val any) (any, error) {
+	d, ok, err := ToDecimal(val)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// DecimalArgsFieldParser sets a Decimal, an integer or a string field. The value is rescaled to
+// the `scale` argument if given, rounded by the `round` argument, half_up by default.
+// An integer field is set with the unscaled value, i.e. in minor units of the scale.
+func DecimalArgsFieldParser(dest reflect.Value, val any, args Args) error {
+	return setDecimal(dest, val, args, 0)
+}
+
+// CurrencyArgsFieldParser is DecimalArgsFieldParser with a scale of 2 by default,
+// so that an int64 field gets cents of "¥1,234.50".
+func CurrencyArgsFieldParser(dest reflect.Value, val any, args Args) error {
+	return setDecimal(dest, val, args, 2)
+}
+
+func setDecimal(dest reflect.Value, val any, args Args, defaultScale int) error {
+	d, ok, err := ToDecimal(val)
+	if err != nil || !ok {
+		return err
+	}
+
+	v := AllocIndirect(dest)
+
+	isInt := v.Type() != DecimalType && v.Kind() != reflect.String
+	if !isInt {
+		// a Decimal or string field keeps the scale of the value unless the argument is given.
+		defaultScale = -1
+	}
+
+	scale, rescale, err := argsScale(args, defaultScale)
+	if err != nil {
+		return err
+	}
+
+	if rescale {
+		d, err = d.Rescale(scale, RoundMode(args.Get("round", string(RoundHalfUp))))
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case v.Type() == DecimalType:
+		v.Set(reflect.ValueOf(d))
+	case v.Kind() == reflect.String:
+		v.SetString(d.String())
+	default:
+		return SetNumber(v, d.Unscaled, RoundError)
+	}
+
+	return nil
+}
+
+// DecimalArgsFieldEncoder encodes a Decimal, a string or an integer in minor units of the
+// `scale` argument as the float64 number of currency and number fields.
+func DecimalArgsFieldEncoder(src reflect.Value, args Args) (any, error) {
+	return encodeDecimal(src, args, 0)
+}
+
+// CurrencyArgsFieldEncoder is DecimalArgsFieldEncoder with a scale of 2 by default.
+func CurrencyArgsFieldEncoder(src reflect.Value, args Args) (any, error) {
+	return encodeDecimal(src, args, 2)
+}
+
+func encodeDecimal(src reflect.Value, args Args, defaultScale int) (any, error) {
+	v, ok := indirectValue(src)
+	if !ok {
+		return nil, nil
+	}
+
+	switch {
+	case v.Type() == DecimalType:
+		return v.Interface().(Decimal).Float64(), nil
+	case v.Kind() == reflect.String:
+		if v.String() == "" {
+			return nil, nil
+		}
+		d, err := ParseDecimal(v.String())
+		if err != nil {
+			return nil, err
+		}
+		return d.Float64(), nil
+	}
+
+	unscaled, err := ToInt64(v.Interface(), RoundError)
+	if err != nil {
+		return nil, err
+	}
+
+	scale, _, err := argsScale(args, defaultScale)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDecimal(unscaled, scale).Float64(), nil
+}
@@ -0,0 +1,253 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Decimal
+		wantErr bool
+	}{
+		{s: "0", want: NewDecimal(0, 0)},
+		{s: "1234.50", want: NewDecimal(123450, 2)},
+		{s: "-0.05", want: NewDecimal(-5, 2)},
+		{s: "+7", want: NewDecimal(7, 0)},
+		{s: ".5", want: NewDecimal(5, 1)},
+		{s: "1,234,567.89", want: NewDecimal(123456789, 2)},
+		{s: "12.5%", want: NewDecimal(125, 3)},
+		{s: "-3%", want: NewDecimal(-3, 2)},
+		{s: "¥1,234.50", want: NewDecimal(123450, 2)},
+		{s: "-¥1,234.50", want: NewDecimal(-123450, 2)},
+		{s: "¥-1,234.50", want: NewDecimal(-123450, 2)},
+		{s: "$ 9.99", want: NewDecimal(999, 2)},
+		{s: "€0.1", want: NewDecimal(1, 1)},
+		{s: "CNY 1,000", want: NewDecimal(1000, 0)},
+		{s: "100.00 USD", want: NewDecimal(10000, 2)},
+		{s: "88元", want: NewDecimal(88, 0)},
+		{s: "9223372036854775807", want: NewDecimal(9223372036854775807, 0)},
+		{s: "9223372036854775808", wantErr: true},
+		{s: "0.1234567890123456789", wantErr: true},
+		{s: "", wantErr: true},
+		{s: "¥", wantErr: true},
+		{s: "%", wantErr: true},
+		{s: " % ", wantErr: true},
+		{s: "¥%", wantErr: true},
+		{s: "元%", wantErr: true},
+		{s: ".", wantErr: true},
+		{s: "1.2.3", wantErr: true},
+		{s: "12,34", wantErr: true},
+		{s: "1e3", wantErr: true},
+		{s: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseDecimal(tt.s)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := ParseValue("decimal", "%")
+	assert.ErrorContains(t, err, `invalid decimal "%"`)
+}
+
+func TestDecimalRescale(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       Decimal
+		scale   int
+		mode    RoundMode
+		want    string
+		wantErr bool
+	}{
+		{name: "scale up", d: NewDecimal(15, 1), scale: 3, want: "1.500"},
+		{name: "exact down", d: NewDecimal(1500, 3), scale: 1, want: "1.5"},
+		{name: "error", d: NewDecimal(1505, 3), scale: 2, wantErr: true},
+		{name: "trunc", d: NewDecimal(-1509, 3), scale: 2, mode: RoundTrunc, want: "-1.50"},
+		{name: "floor", d: NewDecimal(-1501, 3), scale: 2, mode: RoundFloor, want: "-1.51"},
+		{name: "ceil", d: NewDecimal(1501, 3), scale: 2, mode: RoundCeil, want: "1.51"},
+		{name: "half up", d: NewDecimal(1505, 3), scale: 2, mode: RoundHalfUp, want: "1.51"},
+		{name: "half up negative", d: NewDecimal(-1505, 3), scale: 2, mode: RoundHalfUp, want: "-1.51"},
+		{name: "half up below", d: NewDecimal(1504, 3), scale: 2, mode: RoundHalfUp, want: "1.50"},
+		{name: "half even", d: NewDecimal(1525, 3), scale: 2, mode: RoundHalfEven, want: "1.52"},
+		{name: "half even odd", d: NewDecimal(1535, 3), scale: 2, mode: RoundHalfEven, want: "1.54"},
+		{name: "overflow", d: NewDecimal(1<<62, 0), scale: 2, wantErr: true},
+		{name: "invalid scale", d: NewDecimal(1, 0), scale: 19, wantErr: true},
+		{name: "invalid mode", d: NewDecimal(15, 1), scale: 0, mode: "up", wantErr: true},
+		{name: "negative scale", d: NewDecimal(5, -1), scale: 2, want: "50.00"},
+		{name: "negative scale overflow", d: NewDecimal(5, -18), scale: 2, wantErr: true},
+		{name: "negative scale zero", d: NewDecimal(0, -18), scale: 2, want: "0.00"},
+		{name: "invalid source scale", d: NewDecimal(5, 20), scale: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.d.Rescale(tt.scale, tt.mode)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestDecimalString(t *testing.T) {
+	assert.Equal(t, "0", NewDecimal(0, 0).String())
+	assert.Equal(t, "0.00", NewDecimal(0, 2).String())
+	assert.Equal(t, "0.05", NewDecimal(5, 2).String())
+	assert.Equal(t, "-0.05", NewDecimal(-5, 2).String())
+	assert.Equal(t, "-1234.50", NewDecimal(-123450, 2).String())
+	assert.Equal(t, 1234.5, NewDecimal(123450, 2).Float64())
+	assert.Equal(t, "50", NewDecimal(5, -1).String())
+	assert.Equal(t, "-1200", NewDecimal(-12, -2).String())
+	assert.Equal(t, "0", NewDecimal(0, -3).String())
+	assert.Equal(t, 50.0, NewDecimal(5, -1).Float64())
+
+	b, err := json.Marshal(map[string]Decimal{"amount": NewDecimal(10, 2)})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"amount":0.10}`, string(b))
+
+	var d Decimal
+	assert.Nil(t, json.Unmarshal([]byte(`0.10`), &d))
+	assert.Equal(t, NewDecimal(10, 2), d)
+	assert.Nil(t, json.Unmarshal([]byte(`"1.5"`), &d))
+	assert.Equal(t, NewDecimal(15, 1), d)
+}
+
+func TestToDecimal(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     any
+		want    Decimal
+		wantOk  bool
+		wantErr bool
+	}{
+		{name: "nil", val: nil},
+		{name: "empty string", val: " "},
+		{name: "empty array", val: []any{}},
+		{name: "float", val: 0.1, want: NewDecimal(1, 1), wantOk: true},
+		{name: "float32", val: float32(0.1), want: NewDecimal(1, 1), wantOk: true},
+		{name: "float money", val: 1234.5, want: NewDecimal(12345, 1), wantOk: true},
+		{name: "int", val: 42, want: NewDecimal(42, 0), wantOk: true},
+		{name: "uint", val: uint8(42), want: NewDecimal(42, 0), wantOk: true},
+		{name: "json number", val: json.Number("19.90"), want: NewDecimal(1990, 2), wantOk: true},
+		{name: "array", val: []any{"¥5"}, want: NewDecimal(5, 0), wantOk: true},
+		{name: "decimal", val: NewDecimal(5, 1), want: NewDecimal(5, 1), wantOk: true},
+		{name: "uint overflow", val: uint64(1 << 63), wantErr: true},
+		{name: "bool", val: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := ToDecimal(tt.val)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type PriceObj struct {
+	Amount   Decimal  `json:"amount" key:"金额"`
+	Price    Decimal  `json:"price" key:"单价" parser:"decimal(scale=2)"`
+	Cents    int64    `json:"cents" key:"分" parser:"currency"`
+	Mills    int64    `json:"mills" key:"厘" parser:"currency(scale=3)"`
+	Rate     *Decimal `json:"rate" key:"费率" parser:"decimal(scale=4,round=half_even)"`
+	Text     string   `json:"text" key:"文本" parser:"currency"`
+	Optional *Decimal `json:"optional" key:"可选"`
+}
+
+func TestParseDecimalFields(t *testing.T) {
+	obj := &PriceObj{}
+	err := Parse(obj, map[string]any{
+		"金额": 0.1,
+		"单价": "¥1,234.5",
+		"分":  1234.565,
+		"厘":  "¥-2.5",
+		"费率": "12.345%",
+		"文本": 19.9,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, NewDecimal(1, 1), obj.Amount)
+	assert.Equal(t, NewDecimal(123450, 2), obj.Price)
+	assert.Equal(t, int64(123457), obj.Cents)
+	assert.Equal(t, int64(-2500), obj.Mills)
+	assert.Equal(t, NewDecimal(1234, 4), *obj.Rate)
+	assert.Equal(t, "19.9", obj.Text)
+	assert.Nil(t, obj.Optional)
+
+	m, err := Encode(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"金额": 0.1,
+		"单价": 1234.5,
+		"分":  1234.57,
+		"厘":  -2.5,
+		"费率": 0.1234,
+		"文本": 19.9,
+	}, m)
+
+	assert.NotNil(t, Parse(&PriceObj{}, map[string]any{"分": "abc"}))
+	assert.NotNil(t, Parse(&PriceObj{}, map[string]any{"单价": true}))
+
+	v, err := ParseValue("currency", "¥1,234.50")
+	assert.Nil(t, err)
+	assert.Equal(t, NewDecimal(123450, 2), v)
+}
+
+type InvalidDecimalObj struct {
+	Amount float64 `json:"amount" key:"金额" parser:"decimal"`
+}
+
+func TestValidateDecimal(t *testing.T) {
+	assert.Nil(t, Validate[PriceObj]())
+
+	var typeErr *FieldTypeError
+	assert.ErrorAs(t, Validate[InvalidDecimalObj](), &typeErr)
+
+	type InvalidScaleObj struct {
+		Price Decimal `json:"price" key:"单价" parser:"decimal(scale=-1)"`
+	}
+	assert.ErrorContains(t, Validate[InvalidScaleObj](), "parser decimal: invalid scale -1")
+
+	type InvalidCurrencyRoundObj struct {
+		Cents int64 `json:"cents" key:"实付" parser:"currency(round=nearest)"`
+	}
+	assert.ErrorContains(t, Validate[InvalidCurrencyRoundObj](), "parser currency: invalid round mode nearest")
+}
@@ -0,0 +1,211 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldEncoder converts a struct field back to the value shape the bitable api expects.
+// A nil result means the field has no value and is left out of the encoded map.
+type FieldEncoder func(src reflect.Value) (any, error)
+
+// SetFieldEncoder registers an encoder into the default parser.
+func SetFieldEncoder(name string, encoder FieldEncoder) {
+	defaultParser.SetFieldEncoder(name, encoder)
+}
+
+// GetValue returns the value held by src, dereferencing pointers.
+// It returns nil for a nil pointer, the reverse of SetValue.
+func GetValue(src reflect.Value) any {
+	v, ok := indirectValue(src)
+	if !ok {
+		return nil
+	}
+
+	return v.Interface()
+}
+
+func indirectValue(src reflect.Value) (reflect.Value, bool) {
+	for src.Kind() == reflect.Ptr {
+		if src.IsNil() {
+			return src, false
+		}
+		src = src.Elem()
+	}
+
+	return src, true
+}
+
+// Encode converts src into a record field map using the default parser.
+func Encode(src any) (map[string]any, error) {
+	return defaultParser.Encode(src)
+}
+
+// Encode converts a struct tagged with `key` and `parser` into a record field map,
+// the reverse of Parse. Fields whose parser has no registered encoder are read only
+// and skipped, like fields with the `readonly` key option. Two written fields of the same key fail.
+func (p *Parser) Encode(src any) (m map[string]any, perr error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			perr = fmt.Errorf("encode error: %v", panicErr)
+		}
+	}()
+
+	srcValue := reflect.ValueOf(src)
+	for srcValue.Kind() == reflect.Ptr {
+		if srcValue.IsNil() {
+			return nil, fmt.Errorf("encode error: nil %T", src)
+		}
+		srcValue = srcValue.Elem()
+	}
+
+	if srcValue.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("encode error: invalid type %T", src)
+	}
+
+	return p.encodeStruct(srcValue)
+}
+
+func (p *Parser) encodeStruct(v reflect.Value) (map[string]any, error) {
+	configs, err := p.getTypeFieldConfigs(v.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]any, len(configs))
+	writers := make(map[string]string, len(configs))
+	for _, config := range configs {
+		if config.ReadOnly || (!config.Nested && config.Encoder == nil) {
+			continue
+		}
+
+		if writer, ok := writers[config.Key]; ok {
+			return nil, fmt.Errorf("encode error: fields %s and %s write key %s, tag one of them readonly",
+				writer, config.Name, config.Key)
+		}
+		writers[config.Key] = config.Name
+
+		field, ok := fieldByIndex(v, config.Index, false)
+		if !ok || (config.OmitEmpty && field.IsZero()) {
+			continue
+		}
+
+		var val any
+		var encodeErr error
+		if config.Nested {
+			val, encodeErr = p.encodeNested(field)
+		} else {
+			val, encodeErr = config.Encoder(field, config.Args)
+		}
+
+		if encodeErr != nil {
+			return nil, fmt.Errorf("encode error: %v, field: %s", encodeErr, config.Name)
+		}
+
+		if val == nil {
+			continue
+		}
+
+		m[config.Key] = val
+	}
+
+	return m, nil
+}
+
+func (p *Parser) encodeNested(field reflect.Value) (any, error) {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil, nil
+		}
+		field = field.Elem()
+	}
+
+	m, err := p.encodeStruct(field)
+	if err != nil || len(m) == 0 {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func StringFieldEncoder(src reflect.Value) (any, error) {
+	val := GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	return ParseStringField(val), nil
+}
+
+func IntFieldEncoder(src reflect.Value) (any, error) {
+	v, ok := indirectValue(src)
+	if !ok {
+		return nil, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), nil
+	default:
+		return ParseIntField(v.Interface())
+	}
+}
+
+func FloatFieldEncoder(src reflect.Value) (any, error) {
+	v, ok := indirectValue(src)
+	if !ok {
+		return nil, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	default:
+		return ParseFloatField(v.Interface())
+	}
+}
+
+// ArrayToStringFieldEncoder splits a joined string back into an option array,
+// the wire shape of multiple select fields.
+func ArrayToStringFieldEncoder(src reflect.Value) (any, error) {
+	return ArrayToStringArgsFieldEncoder(src, nil)
+}
+
+// ArrayToStringArgsFieldEncoder splits the string with the `sep` argument, "," by default.
+func ArrayToStringArgsFieldEncoder(src reflect.Value, args Args) (any, error) {
+	val := GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	s, ok := val.(string)
+	if !ok {
+		return nil, fmt.Errorf("ArrayToStringFieldEncoder: invalid type %T", val)
+	}
+
+	if s == "" {
+		return nil, nil
+	}
+
+	return strings.Split(s, args.Get("sep", ",")), nil
+}
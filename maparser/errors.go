@@ -0,0 +1,91 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrRequired is the cause of a *FieldError when a required field is absent or empty.
+var ErrRequired = errors.New("required field is empty")
+
+// FieldError reports a failure to parse one field.
+type FieldError struct {
+	Field  string
+	Key    string
+	Parser string
+	Value  any
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("parse error: %v, field: %s, key: %s, parser: %s, value: %v",
+		e.Err, e.Field, e.Key, e.Parser, e.Value)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors collects every field failure of a record, returned by a parser
+// created with WithCollectErrors.
+type ParseErrors struct {
+	Errors []*FieldError
+}
+
+func (e *ParseErrors) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e *ParseErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// UnmatchedError reports the drift between a struct and the records, returned by a parser
+// created with WithStrict.
+type UnmatchedError struct {
+	// UnknownKeys are record keys claimed by no field, sorted.
+	UnknownKeys []string
+
+	// MissingFields are the fields whose key is not found, in declaration order,
+	// MissingKeys are their primary keys.
+	MissingFields []string
+	MissingKeys   []string
+}
+
+func (e *UnmatchedError) Error() string {
+	missing := make([]string, 0, len(e.MissingFields))
+	for i, name := range e.MissingFields {
+		missing = append(missing, fmt.Sprintf("%s(%s)", name, e.MissingKeys[i]))
+	}
+
+	return fmt.Sprintf("unmatched keys: [%s], missing fields: [%s]",
+		strings.Join(e.UnknownKeys, ", "), strings.Join(missing, ", "))
+}
@@ -0,0 +1,101 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RowError reports the index of the record that failed in ParseSlice.
+type RowError struct {
+	Index int
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Index, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ParseAs parses a record field map into a new T, T can be a struct or a pointer to struct.
+func ParseAs[T any](m map[string]any) (T, error) {
+	return ParseAsWith[T](defaultParser, m)
+}
+
+// ParseSlice parses record field maps into a slice of T, T can be a struct or a pointer to struct.
+// A failed record is reported as a *RowError.
+func ParseSlice[T any](items []map[string]any) ([]T, error) {
+	return ParseSliceWith[T](defaultParser, items)
+}
+
+// ParseAsWith is ParseAs using parser p. The parsed value is also returned with an *UnmatchedError
+// of a strict parser.
+func ParseAsWith[T any](p *Parser, m map[string]any) (T, error) {
+	t, state, err := parseAs[T](p, m)
+	if err != nil {
+		return t, err
+	}
+
+	return t, state.unmatchedError()
+}
+
+// ParseSliceWith is ParseSlice using parser p. A strict parser reports the unknown keys of all records
+// and the fields found in no record as an *UnmatchedError, returned together with the parsed slice.
+func ParseSliceWith[T any](p *Parser, items []map[string]any) ([]T, error) {
+	list := make([]T, 0, len(items))
+	unmatched := &unmatchedRecords{}
+
+	for i, m := range items {
+		t, state, err := parseAs[T](p, m)
+		if err != nil {
+			return nil, &RowError{Index: i, Err: err}
+		}
+		list = append(list, t)
+		unmatched.add(state)
+	}
+
+	return list, unmatched.unmatchedError()
+}
+
+func parseAs[T any](p *Parser, m map[string]any) (T, *parseState, error) {
+	var t T
+
+	dest, result := newDest[T](&t)
+	state, err := p.parse(dest, m)
+	if err != nil {
+		return t, nil, err
+	}
+
+	return result(), state, nil
+}
+
+// newDest returns the value to parse into for t and a function returning the parsed T.
+// A pointer T is allocated, so that ParseAs[*Record] returns a non-nil record.
+func newDest[T any](t *T) (any, func() T) {
+	typ := reflect.TypeOf(t).Elem()
+	if typ.Kind() != reflect.Ptr {
+		return t, func() T { return *t }
+	}
+
+	v := reflect.New(typ.Elem())
+	return v.Interface(), func() T { return v.Interface().(T) }
+}
@@ -20,7 +20,6 @@ package maparser
 import (
 	"fmt"
 	"reflect"
-	"strconv"
 	"strings"
 )
 
@@ -29,26 +28,61 @@ type (
 	ValueParser func(val any) (any, error)
 )
 
-var fieldParserMap = map[string]FieldParser{}
+var defaultParser = New()
 
-var valueParserMap = map[string]ValueParser{}
+// Default returns the parser used by the package level functions.
+func Default() *Parser {
+	return defaultParser
+}
 
-func init() {
-	SetFieldParser("string", StringValueParser, StringFieldParser)
-	SetFieldParser("int", IntValueParser, IntFieldParser)
-	SetFieldParser("float", FloatValueParser, FloatFieldParser)
-	SetFieldParser("array_to_string", ArrayToStringValueParser, ArrayToStringFieldParser)
-	SetFieldParser("array_first_int64", ArrayFirstInt64ValueParser, ArrayFirstInt64FieldParser)
+func registerBuiltinParsers(p *Parser) {
+	p.SetFieldParser("string", StringValueParser, StringFieldParser)
+	p.SetArgsFieldParser("int", IntValueParser, IntArgsFieldParser)
+	p.SetFieldParser("float", FloatValueParser, FloatFieldParser)
+	p.SetArgsFieldParser("array_to_string", ArrayToStringValueParser, ArrayToStringArgsFieldParser)
+	p.SetArgsFieldParser("array_first_int64", ArrayFirstInt64ValueParser, ArrayFirstInt64ArgsFieldParser)
+	p.SetArgsFieldParser("bool", BoolValueParser, BoolArgsFieldParser)
+	p.SetArgsFieldParser("checkbox", BoolValueParser, BoolArgsFieldParser)
+	p.SetArgsFieldParser("decimal", DecimalValueParser, DecimalArgsFieldParser)
+	p.SetArgsFieldParser("currency", DecimalValueParser, CurrencyArgsFieldParser)
+
+	p.SetFieldEncoder("string", StringFieldEncoder)
+	p.SetFieldEncoder("int", IntFieldEncoder)
+	p.SetFieldEncoder("float", FloatFieldEncoder)
+	p.SetArgsFieldEncoder("array_to_string", ArrayToStringArgsFieldEncoder)
+	p.SetFieldEncoder("array_first_int64", IntFieldEncoder)
+	p.SetArgsFieldEncoder("bool", BoolArgsFieldEncoder)
+	p.SetArgsFieldEncoder("checkbox", BoolArgsFieldEncoder)
+	p.SetArgsFieldEncoder("decimal", DecimalArgsFieldEncoder)
+	p.SetArgsFieldEncoder("currency", CurrencyArgsFieldEncoder)
+
+	p.SetFieldTarget("string", StringTarget)
+	p.SetFieldTarget("array_to_string", StringTarget)
+	p.SetFieldTarget("int", IntTarget)
+	p.SetFieldTarget("array_first_int64", IntTarget)
+	p.SetFieldTarget("float", FloatTarget)
+	p.SetFieldTarget("bool", BoolTarget)
+	p.SetFieldTarget("checkbox", BoolTarget)
+	p.SetFieldTarget("decimal", DecimalTarget)
+	p.SetFieldTarget("currency", DecimalTarget)
+
+	p.SetArgsValidator("int", ValidateRoundArgs)
+	p.SetArgsValidator("array_first_int64", ValidateRoundArgs)
+	p.SetArgsValidator("decimal", validateDecimalArgs)
+	p.SetArgsValidator("currency", validateDecimalArgs)
+
+	p.SetTypeParser(DecimalType, "decimal")
+	p.SetTypeParser(reflect.PointerTo(DecimalType), "decimal")
 }
 
-func SetFieldParser(name string, valueParser ValueParser, fieldParser FieldParser) {
-	valueParserMap[name] = valueParser
-	fieldParserMap[name] = fieldParser
+// SetTypeParser sets the parser inferred for fields of type t in the default parser.
+func SetTypeParser(t reflect.Type, parserName string) {
+	defaultParser.SetTypeParser(t, parserName)
 }
 
-type fieldConfig struct {
-	Key    string
-	Parser FieldParser
+// SetFieldParser registers a parser into the default parser.
+func SetFieldParser(name string, valueParser ValueParser, fieldParser FieldParser) {
+	defaultParser.SetFieldParser(name, valueParser, fieldParser)
 }
 
 // PackPtr pack a Ptr value
@@ -66,73 +100,22 @@ func SetValue(dest reflect.Value, val any) {
 	dest.Set(v)
 }
 
-var typeMapFieldConfigMap = map[reflect.Type]map[string]fieldConfig{}
-
-func getTypeMapFieldConfig(t reflect.Type) (map[string]fieldConfig, error) {
-	c, ok := typeMapFieldConfigMap[t]
-	if !ok {
-		c = make(map[string]fieldConfig)
-		typeMapFieldConfigMap[t] = c
-
-		for i := 0; i < t.NumField(); i++ {
-			field := t.Field(i)
-			key, tagOk := field.Tag.Lookup("key")
-			if !tagOk {
-				continue
-			}
-			parserName, parserOk := field.Tag.Lookup("parser")
-			if !parserOk {
-				parserName = "string"
-			}
-
-			parser, parserOk := fieldParserMap[parserName]
-			if !parserOk {
-				return nil, fmt.Errorf("invalid parser %s", parserName)
-			}
-
-			c[field.Name] = fieldConfig{
-				Key:    key,
-				Parser: parser,
-			}
+// AllocIndirect returns the value dest points to, allocating nil pointers,
+// so that a parser sets the fields of type T and *T alike.
+func AllocIndirect(dest reflect.Value) reflect.Value {
+	for dest.Kind() == reflect.Ptr {
+		if dest.IsNil() {
+			dest.Set(reflect.New(dest.Type().Elem()))
 		}
+		dest = dest.Elem()
 	}
 
-	return c, nil
+	return dest
 }
 
-func Parse(dest any, m map[string]any) (perr error) {
-	defer func() {
-		if panicErr := recover(); panicErr != nil {
-			perr = fmt.Errorf("parse error: %v", panicErr)
-		}
-	}()
-
-	destValue := reflect.ValueOf(dest)
-	destType := reflect.TypeOf(dest)
-	for destType.Kind() == reflect.Ptr {
-		destType = destType.Elem()
-		destValue = destValue.Elem()
-	}
-
-	fieldConfigMap, err := getTypeMapFieldConfig(destType)
-	if err != nil {
-		return err
-	}
-
-	var parseErr error
-	for fieldName, config := range fieldConfigMap {
-		val := m[config.Key]
-		field := destValue.FieldByName(fieldName)
-		if !field.IsValid() {
-			continue
-		}
-
-		if parseErr = config.Parser(field, val); parseErr != nil {
-			return fmt.Errorf("parse error: %v, field: %s, value: %v ", parseErr, fieldName, val)
-		}
-	}
-
-	return nil
+// Parse parses a record field map into dest using the default parser.
+func Parse(dest any, m map[string]any) error {
+	return defaultParser.Parse(dest, m)
 }
 
 func StringValueParser(val any) (any, error) {
@@ -164,7 +147,7 @@ func StringFieldParser(dest reflect.Value, val any) error {
 		return nil
 	}
 
-	dest.SetString(s.(string))
+	AllocIndirect(dest).SetString(s.(string))
 
 	return nil
 }
@@ -182,6 +165,10 @@ func ParseStringField(val any) string {
 }
 
 func ArrayToStringValueParser(val any) (any, error) {
+	return joinStringArray(val, ",")
+}
+
+func joinStringArray(val any, sep string) (any, error) {
 	if val == nil {
 		return nil, nil
 	}
@@ -198,7 +185,7 @@ func ArrayToStringValueParser(val any) (any, error) {
 
 	arr, ok := val.([]string)
 	if ok {
-		return strings.Join(arr, ","), nil
+		return strings.Join(arr, sep), nil
 	}
 
 	faceArr, ok := val.([]interface{})
@@ -213,7 +200,7 @@ func ArrayToStringValueParser(val any) (any, error) {
 			arr = append(arr, s)
 		}
 
-		return strings.Join(arr, ","), nil
+		return strings.Join(arr, sep), nil
 	}
 
 	return nil, fmt.Errorf("ArrayToStringValueParser: invalid type %T", val)
@@ -230,210 +217,65 @@ func isArrAllString(arr []interface{}) bool {
 }
 
 func ArrayToStringFieldParser(dest reflect.Value, val any) error {
-	s, err := ArrayToStringValueParser(val)
+	return ArrayToStringArgsFieldParser(dest, val, nil)
+}
+
+// ArrayToStringArgsFieldParser joins the array with the `sep` argument, "," by default.
+func ArrayToStringArgsFieldParser(dest reflect.Value, val any, args Args) error {
+	s, err := joinStringArray(val, args.Get("sep", ","))
 	if err != nil {
 		return err
 	}
 	if s == nil {
 		return nil
 	}
-	dest.SetString(s.(string))
+	AllocIndirect(dest).SetString(s.(string))
 	return nil
 }
 
 func IntValueParser(val any) (any, error) {
-	if val == nil {
-		return nil, nil
-	}
-
-	switch v := val.(type) {
-	case []interface{}:
-		if len(v) > 0 {
-			val = v[0]
-		}
+	n, err := toNumber(val)
+	if err != nil || n.kind == numberNone {
+		return nil, err
 	}
 
-	switch v := val.(type) {
-	case int:
-		return int64(v), nil
-	case int32:
-		return int64(v), nil
-	case int64:
-		return int64(v), nil
-	case float32:
-		return int64(v), nil
-	case float64:
-		return int64(v), nil
-	case string:
-		return strconv.Atoi(v)
-	default:
-		return nil, fmt.Errorf("invalid type %T", val)
-	}
+	return n.int64(RoundTrunc)
 }
 
 func IntFieldParser(dest reflect.Value, val any) error {
-	if val == nil {
-		return nil
-	}
-
-	switch v := val.(type) {
-	case int:
-		dest.SetInt(int64(v))
-	case int32:
-		dest.SetInt(int64(v))
-	case int64:
-		dest.SetInt(v)
-	case float32:
-		dest.SetInt(int64(v))
-	case float64:
-		dest.SetInt(int64(v))
-	case string:
-		if v == "" {
-			return nil
-		}
-
-		i, err := strconv.ParseInt(v, 10, 64)
-		if err != nil {
-			return err
-		}
-		dest.SetInt(i)
-	default:
-		return fmt.Errorf("invalid type %T", val)
-	}
+	return IntArgsFieldParser(dest, val, nil)
+}
 
-	return nil
+// IntArgsFieldParser sets an int or uint field, a fractional number is rounded by the `round` argument,
+// truncated by default.
+func IntArgsFieldParser(dest reflect.Value, val any, args Args) error {
+	return SetNumber(dest, val, ArgsRoundMode(args))
 }
 
+// ParseIntField converts a record value to int64, truncating a fractional number.
 func ParseIntField(val any) (int64, error) {
-	if val == nil {
-		return 0, nil
-	}
-
-	switch v := val.(type) {
-	case int:
-		return int64(v), nil
-	case int32:
-		return int64(v), nil
-	case int64:
-		return v, nil
-	case float32:
-		return int64(v), nil
-	case float64:
-		return int64(v), nil
-	case string:
-		return strconv.ParseInt(v, 10, 64)
-	default:
-		return 0, fmt.Errorf("invalid type: %T", val)
-	}
+	return ToInt64(val, RoundTrunc)
 }
 
 func FloatValueParser(val any) (any, error) {
-	if val == nil {
-		return nil, nil
-	}
-
-	switch v := val.(type) {
-	case []interface{}:
-		if len(v) > 0 {
-			val = v[0]
-		}
+	n, err := toNumber(val)
+	if err != nil || n.kind == numberNone {
+		return nil, err
 	}
 
-	switch v := val.(type) {
-	case int:
-		return float64(v), nil
-	case int32:
-		return float64(v), nil
-	case int64:
-		return float64(v), nil
-	case float32:
-		return float64(v), nil
-	case float64:
-		return v, nil
-	case string:
-		return strToFloat(v)
-	default:
-		return nil, fmt.Errorf("invalid type %T", val)
-	}
+	return n.float64(), nil
 }
 
 func FloatFieldParser(dest reflect.Value, val any) error {
-	if val == nil {
-		return nil
-	}
-
-	switch v := val.(type) {
-	case int:
-		dest.SetFloat(float64(v))
-	case int32:
-		dest.SetFloat(float64(v))
-	case int64:
-		dest.SetFloat(float64(v))
-	case float32:
-		dest.SetFloat(float64(v))
-	case float64:
-		dest.SetFloat(v)
-	case string:
-		f, err := strToFloat(v)
-		if err != nil {
-			return fmt.Errorf("FloatFieldParser: invalid flaot %s, error: %v", val, err)
-		}
-		dest.SetFloat(f)
-	default:
-		str := fmt.Sprintf("%v", val)
-
-		f, err := strToFloat(str)
-		if err != nil {
-			return fmt.Errorf("FloatFieldParser: invalid flaot %s, error: %v", str, err)
-		}
-
-		dest.SetFloat(f)
+	if err := SetNumber(dest, val, RoundError); err != nil {
+		return fmt.Errorf("FloatFieldParser: %v", err)
 	}
 
 	return nil
 }
 
 func ParseFloatField(val any) (float64, error) {
-	switch v := val.(type) {
-	case int:
-		return float64(v), nil
-	case int32:
-		return float64(v), nil
-	case int64:
-		return float64(v), nil
-	case float32:
-		return float64(v), nil
-	case float64:
-		return v, nil
-	case string:
-		f, err := strToFloat(v)
-		if err != nil {
-			return 0, fmt.Errorf("FloatFieldParser: invalid flaot %s, error: %v", val, err)
-		}
-		return f, nil
-	default:
-		str := fmt.Sprintf("%v", val)
-
-		f, err := strToFloat(str)
-		if err != nil {
-			return 0, fmt.Errorf("FloatFieldParser: invalid flaot %s, error: %v", str, err)
-		}
-
-		return f, nil
-	}
-}
-
-func strToFloat(v string) (float64, error) {
-	if strings.HasSuffix(v, "%") {
-		v = strings.TrimSuffix(v, "%")
-		f, err := strconv.ParseFloat(v, 64)
-		if err != nil {
-			return 0, fmt.Errorf("invalid float %s", v)
-		}
-		return f / 100, nil
-	}
-
-	return strconv.ParseFloat(v, 64)
+	return ToFloat64(val)
 }
 
 func ArrayFirstInt64ValueParser(val any) (any, error) {
@@ -472,16 +314,26 @@ func ArrayFirstInt64ValueParser(val any) (any, error) {
 }
 
 func ArrayFirstInt64FieldParser(dest reflect.Value, val any) error {
+	return ArrayFirstInt64ArgsFieldParser(dest, val, nil)
+}
+
+// ArrayFirstInt64ArgsFieldParser sets the first element of an array into an int or uint field,
+// a fractional number is rounded by the `round` argument, truncated by default.
+func ArrayFirstInt64ArgsFieldParser(dest reflect.Value, val any, args Args) error {
 	if val == nil {
 		return nil
 	}
 
-	value, err := ArrayFirstInt64ValueParser(val)
-	if err != nil {
-		return err
+	if v := reflect.ValueOf(val); v.Kind() == reflect.Slice {
+		if v.Len() == 0 {
+			return SetNumber(dest, int64(0), RoundTrunc)
+		}
+		val = v.Index(0).Interface()
 	}
 
-	dest.SetInt(value.(int64))
+	if val == nil {
+		return SetNumber(dest, int64(0), RoundTrunc)
+	}
 
-	return nil
+	return SetNumber(dest, val, ArgsRoundMode(args))
 }
@@ -0,0 +1,107 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type BenchObj struct {
+	Id      int64   `json:"id" key:"编号" parser:"int"`
+	Name    string  `json:"name" key:"姓名" parser:"string"`
+	Dept    string  `json:"dept" key:"部门" parser:"string"`
+	Tags    string  `json:"tags" key:"标签" parser:"array_to_string"`
+	Score   float64 `json:"score" key:"分数" parser:"float"`
+	Level   int64   `json:"level" key:"级别" parser:"array_first_int64"`
+	Remark  string  `json:"remark" key:"备注" parser:"string"`
+	Ignored string  `json:"ignored"`
+}
+
+const benchRecordCount = 10000
+
+func benchRecords() []map[string]any {
+	records := make([]map[string]any, 0, benchRecordCount)
+	for i := 0; i < benchRecordCount; i++ {
+		records = append(records, map[string]any{
+			"编号": float64(i),
+			"姓名": fmt.Sprintf("name-%d", i),
+			"部门": "dept",
+			"标签": []any{"a", "b"},
+			"分数": 99.5,
+			"级别": []any{float64(3)},
+			"备注": "remark",
+		})
+	}
+
+	return records
+}
+
+// parseByName is the former approach, visiting a map of field configs and looking fields up by name.
+func parseByName(configMap map[string]fieldConfig, dest any, m map[string]any) error {
+	destValue := reflect.ValueOf(dest).Elem()
+	for fieldName, config := range configMap {
+		field := destValue.FieldByName(fieldName)
+		if !field.IsValid() {
+			continue
+		}
+
+		if err := config.Parser(field, m[config.Key], config.Args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func BenchmarkParseFieldByName(b *testing.B) {
+	records := benchRecords()
+
+	configs, err := defaultParser.getTypeFieldConfigs(reflect.TypeOf(BenchObj{}))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	configMap := make(map[string]fieldConfig, len(configs))
+	for _, config := range configs {
+		configMap[config.Name] = config
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, m := range records {
+			if err := parseByName(configMap, &BenchObj{}, m); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkParseFieldIndex(b *testing.B) {
+	records := benchRecords()
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, m := range records {
+			if err := Parse(&BenchObj{}, m); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -18,6 +18,11 @@
 package maparser
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -46,3 +51,844 @@ func TestParse(t *testing.T) {
 	assert.Equal(t, 123, obj.Int)
 	assert.Equal(t, int64(456), obj.ArrInt64Value)
 }
+
+type EncodeObj struct {
+	Str    string   `json:"str" key:"str" parser:"string"`
+	Int    int      `json:"int" key:"int" parser:"int"`
+	Float  *float64 `json:"float" key:"float" parser:"float"`
+	Tags   string   `json:"tags" key:"tags" parser:"array_to_string"`
+	Ignore string   `json:"ignore"`
+}
+
+func TestEncode(t *testing.T) {
+	f := 1.5
+	m, err := Encode(&EncodeObj{
+		Str:    "test",
+		Int:    123,
+		Float:  &f,
+		Tags:   "a,b",
+		Ignore: "ignore",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"str":   "test",
+		"int":   int64(123),
+		"float": 1.5,
+		"tags":  []string{"a", "b"},
+	}, m)
+
+	m, err = Encode(EncodeObj{Str: "test"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"str": "test",
+		"int": int64(0),
+	}, m)
+
+	_, err = Encode((*EncodeObj)(nil))
+	assert.NotNil(t, err)
+
+	obj := &EncodeObj{}
+	assert.Nil(t, Parse(obj, map[string]any{"str": "test", "int": 1, "tags": []any{"a", "b"}}))
+	m, err = Encode(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, m["tags"])
+}
+
+func TestParseAs(t *testing.T) {
+	m := map[string]any{"str": "test", "int": 123}
+
+	obj, err := ParseAs[Obj](m)
+	assert.Nil(t, err)
+	assert.Equal(t, "test", obj.Str)
+	assert.Equal(t, 123, obj.Int)
+
+	ptr, err := ParseAs[*Obj](m)
+	assert.Nil(t, err)
+	assert.NotNil(t, ptr)
+	assert.Equal(t, "test", ptr.Str)
+}
+
+func TestParseSlice(t *testing.T) {
+	items := []map[string]any{
+		{"str": "a", "int": 1},
+		{"str": "b", "int": "2"},
+	}
+
+	list, err := ParseSlice[*Obj](items)
+	assert.Nil(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "b", list[1].Str)
+	assert.Equal(t, 2, list[1].Int)
+
+	items = append(items, map[string]any{"int": "x"})
+	_, err = ParseSlice[Obj](items)
+	var rowErr *RowError
+	assert.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 2, rowErr.Index)
+}
+
+type ConcurrentObj struct {
+	Str   string  `json:"str" key:"str" parser:"string"`
+	Int   int64   `json:"int" key:"int" parser:"int"`
+	Float float64 `json:"float" key:"float" parser:"float"`
+	Upper string  `json:"upper" key:"upper" parser:"concurrent_upper"`
+}
+
+// TestParseConcurrent is meant to be run with -race.
+func TestParseConcurrent(t *testing.T) {
+	const goroutines = 32
+	const records = 200
+
+	upper := func(dest reflect.Value, val any) error {
+		dest.SetString(fmt.Sprintf("%v!", val))
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, goroutines)
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			// concurrent registration invalidates the type config cache while parsing.
+			SetFieldParser("concurrent_upper", nil, upper)
+
+			for i := 0; i < records; i++ {
+				m := map[string]any{
+					"str":   fmt.Sprintf("s-%d-%d", g, i),
+					"int":   i,
+					"float": float64(g),
+					"upper": "u",
+				}
+
+				obj := &ConcurrentObj{}
+				if err := Parse(obj, m); err != nil {
+					errCh <- err
+					return
+				}
+
+				if obj.Str != m["str"] || obj.Int != int64(i) || obj.Upper != "u!" {
+					errCh <- fmt.Errorf("unexpected result: %+v", obj)
+					return
+				}
+
+				if _, err := Encode(obj); err != nil {
+					errCh <- err
+					return
+				}
+			}
+		}(g)
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		t.Fatal(err)
+	}
+}
+
+type TenantObj struct {
+	Name string `json:"name" key:"name" parser:"tenant_name"`
+}
+
+func TestParserInstance(t *testing.T) {
+	prefix := func(p string) FieldParser {
+		return func(dest reflect.Value, val any) error {
+			dest.SetString(p + val.(string))
+			return nil
+		}
+	}
+
+	a := New(WithFieldParser("tenant_name", nil, prefix("a:")))
+	b := New(WithFieldParser("tenant_name", nil, prefix("b:")))
+	m := map[string]any{"name": "vogo"}
+
+	objA, err := ParseAsWith[TenantObj](a, m)
+	assert.Nil(t, err)
+	assert.Equal(t, "a:vogo", objA.Name)
+
+	objB, err := ParseAsWith[TenantObj](b, m)
+	assert.Nil(t, err)
+	assert.Equal(t, "b:vogo", objB.Name)
+
+	// not registered in the default parser.
+	assert.NotNil(t, Parse(&TenantObj{}, m))
+
+	c := New(WithParsersFrom(a))
+	objC, err := ParseAsWith[TenantObj](c, m)
+	assert.Nil(t, err)
+	assert.Equal(t, "a:vogo", objC.Name)
+
+	// builtin parsers are available in every instance.
+	obj := &Obj{}
+	assert.Nil(t, a.Parse(obj, map[string]any{"str": "s", "int": 1}))
+	assert.Equal(t, 1, obj.Int)
+}
+
+type BrokenObj struct {
+	Int     int    `json:"int" key:"整数" parser:"int"`
+	Float   int    `json:"float" key:"小数" parser:"panic"`
+	Str     string `json:"str" key:"文本" parser:"string"`
+	Invalid int    `json:"invalid" key:"无效" parser:"broken"`
+}
+
+var errBroken = errors.New("broken")
+
+func TestParseCollectErrors(t *testing.T) {
+	p := New(WithFieldParser("broken", nil, func(dest reflect.Value, val any) error {
+		return errBroken
+	}), WithFieldParser("panic", nil, func(dest reflect.Value, val any) error {
+		dest.SetFloat(val.(float64))
+		return nil
+	}))
+	m := map[string]any{
+		"整数": "x",
+		"小数": 1.5,
+		"文本": "ok",
+		"无效": 1,
+	}
+
+	obj := &BrokenObj{}
+	err := p.Parse(obj, m)
+	var fieldErr *FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+
+	p = New(WithCollectErrors(), WithParsersFrom(p))
+	obj = &BrokenObj{}
+	err = p.Parse(obj, m)
+	assert.Equal(t, "ok", obj.Str)
+
+	var parseErrs *ParseErrors
+	assert.ErrorAs(t, err, &parseErrs)
+	assert.Len(t, parseErrs.Errors, 3)
+	assert.ErrorIs(t, err, errBroken)
+
+	// errors follow the field declaration order.
+	var names []string
+	fields := map[string]*FieldError{}
+	for _, e := range parseErrs.Errors {
+		names = append(names, e.Field)
+		fields[e.Field] = e
+	}
+	assert.Equal(t, []string{"Int", "Float", "Invalid"}, names)
+	assert.Equal(t, "整数", fields["Int"].Key)
+	assert.Equal(t, "int", fields["Int"].Parser)
+	assert.Equal(t, "x", fields["Int"].Value)
+	// a panic setting a float into an int field is reported as a field error.
+	assert.Equal(t, "panic", fields["Float"].Parser)
+	assert.Equal(t, "broken", fields["Invalid"].Parser)
+}
+
+type AuditFields struct {
+	Creator string `json:"creator" key:"创建人" parser:"string"`
+	Version int    `json:"version" key:"版本" parser:"int"`
+}
+
+type ReviewInfo struct {
+	Status string `json:"status" key:"状态" parser:"string"`
+	Score  int    `json:"score" key:"分数" parser:"int"`
+}
+
+type NestedObj struct {
+	AuditFields
+	Name      string      `json:"name" key:"姓名" parser:"string"`
+	Review    ReviewInfo  `json:"review" prefix:"审核"`
+	Approval  *ReviewInfo `json:"approval" prefix:"审批"`
+	Detail    *ReviewInfo `json:"detail" key:"详情"`
+	NotParsed *ReviewInfo `json:"not_parsed"`
+}
+
+type PtrEmbeddedObj struct {
+	*AuditFields
+	Name string `json:"name" key:"姓名" parser:"string"`
+}
+
+func TestParseNested(t *testing.T) {
+	m := map[string]any{
+		"创建人":  "vogo",
+		"版本":   3,
+		"姓名":   "name",
+		"审核状态": "通过",
+		"审核分数": "90",
+		"详情": map[string]any{
+			"状态": "done",
+			"分数": 1,
+		},
+	}
+
+	obj := &NestedObj{}
+	assert.Nil(t, Parse(obj, m))
+	assert.Equal(t, "vogo", obj.Creator)
+	assert.Equal(t, 3, obj.Version)
+	assert.Equal(t, "name", obj.Name)
+	assert.Equal(t, ReviewInfo{Status: "通过", Score: 90}, obj.Review)
+	assert.Nil(t, obj.Approval)
+	assert.Equal(t, &ReviewInfo{Status: "done", Score: 1}, obj.Detail)
+	assert.Nil(t, obj.NotParsed)
+
+	m["审批状态"] = "拒绝"
+	obj = &NestedObj{}
+	assert.Nil(t, Parse(obj, m))
+	assert.Equal(t, &ReviewInfo{Status: "拒绝"}, obj.Approval)
+
+	encoded, err := Encode(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, "vogo", encoded["创建人"])
+	assert.Equal(t, "通过", encoded["审核状态"])
+	assert.Equal(t, "拒绝", encoded["审批状态"])
+	assert.Equal(t, map[string]any{"状态": "done", "分数": int64(1)}, encoded["详情"])
+
+	ptrObj := &PtrEmbeddedObj{}
+	assert.Nil(t, Parse(ptrObj, map[string]any{"姓名": "name"}))
+	assert.Nil(t, ptrObj.AuditFields)
+	assert.Nil(t, Parse(ptrObj, m))
+	assert.Equal(t, "vogo", ptrObj.Creator)
+
+	m["详情"] = map[string]any{"分数": "x"}
+	err = Parse(&NestedObj{}, m)
+	var fieldErr *FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Detail.Score", fieldErr.Field)
+	assert.Equal(t, "详情.分数", fieldErr.Key)
+}
+
+type OptionObj struct {
+	Name  string  `json:"name" key:"姓名,required" parser:"string"`
+	Age   int     `json:"age" key:"年龄" parser:"int" default:"18"`
+	Score float64 `json:"score" key:"分数,omitempty" parser:"float" default:"60"`
+	Dept  string  `json:"dept" key:"部门,omitempty" parser:"string"`
+}
+
+func TestParseKeyOptions(t *testing.T) {
+	obj := &OptionObj{}
+	assert.Nil(t, Parse(obj, map[string]any{"姓名": "vogo", "部门": ""}))
+	assert.Equal(t, OptionObj{Name: "vogo", Age: 18, Score: 60}, *obj)
+
+	obj = &OptionObj{}
+	assert.Nil(t, Parse(obj, map[string]any{"姓名": "vogo", "年龄": 20, "分数": 99.5}))
+	assert.Equal(t, 20, obj.Age)
+	assert.Equal(t, 99.5, obj.Score)
+
+	err := Parse(&OptionObj{}, map[string]any{"姓名": "", "年龄": 20})
+	assert.ErrorIs(t, err, ErrRequired)
+	var fieldErr *FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "姓名", fieldErr.Key)
+
+	m, err := Encode(&OptionObj{Name: "vogo"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"姓名": "vogo", "年龄": int64(0)}, m)
+
+	type InvalidOption struct {
+		Name string `json:"name" key:"姓名,unknown"`
+	}
+	assert.NotNil(t, Parse(&InvalidOption{}, map[string]any{}))
+
+	// a default the parser rejects fails building the config.
+	type InvalidDefault struct {
+		Age int `json:"age" key:"年龄" parser:"int" default:"abc"`
+	}
+	err = Validate[InvalidDefault]()
+	assert.ErrorContains(t, err, `maparser.InvalidDefault.Age: invalid default "abc"`)
+	assert.NotNil(t, Parse(&InvalidDefault{}, map[string]any{"年龄": 1}))
+
+	// the default used is the value of the field error.
+	failing := false
+	p := New()
+	p.SetFieldParser("flaky", nil, func(dest reflect.Value, val any) error {
+		if failing {
+			return fmt.Errorf("failing")
+		}
+		return StringFieldParser(dest, val)
+	})
+	type FlakyDefault struct {
+		Name string `json:"name" key:"姓名" parser:"flaky" default:"vogo"`
+	}
+	assert.Nil(t, ValidateWith[FlakyDefault](p))
+	failing = true
+	err = p.Parse(&FlakyDefault{}, map[string]any{})
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "vogo", fieldErr.Value)
+}
+
+type AliasObj struct {
+	Name  string `json:"name" key:"员工姓名|姓名" parser:"string"`
+	Email string `json:"email" key:"Email,required" parser:"string"`
+}
+
+func TestParseAliasKeys(t *testing.T) {
+	obj := &AliasObj{}
+	assert.Nil(t, Parse(obj, map[string]any{"姓名": "old", "Email": "a@b.c"}))
+	assert.Equal(t, "old", obj.Name)
+
+	obj = &AliasObj{}
+	assert.Nil(t, Parse(obj, map[string]any{"姓名": "old", "员工姓名": "new", "Email": "a@b.c"}))
+	assert.Equal(t, "new", obj.Name)
+
+	m := map[string]any{"员工 姓名": "folded", " email ": "a@b.c"}
+	assert.ErrorIs(t, Parse(&AliasObj{}, m), ErrRequired)
+
+	matched := map[string]string{}
+	p := New(WithFoldKeys(), WithKeyMatchHook(func(field, key string) {
+		matched[field] = key
+	}))
+
+	obj = &AliasObj{}
+	assert.Nil(t, p.Parse(obj, m))
+	assert.Equal(t, "folded", obj.Name)
+	assert.Equal(t, "a@b.c", obj.Email)
+	assert.Equal(t, map[string]string{"Name": "员工 姓名", "Email": " email "}, matched)
+
+	encoded, err := Encode(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, "folded", encoded["员工姓名"])
+}
+
+type StrictObj struct {
+	Name   string      `json:"name" key:"姓名" parser:"string"`
+	Age    int         `json:"age" key:"年龄" parser:"int"`
+	Detail *ReviewInfo `json:"detail" key:"详情"`
+}
+
+func TestParseStrict(t *testing.T) {
+	p := New(WithStrict())
+
+	obj := &StrictObj{}
+	err := p.Parse(obj, map[string]any{
+		"姓名": "vogo",
+		"部门": "dev",
+		"职位": "eng",
+		"详情": map[string]any{"状态": "ok", "备注": "x"},
+	})
+	assert.Equal(t, "vogo", obj.Name)
+	assert.Equal(t, "ok", obj.Detail.Status)
+
+	var unmatched *UnmatchedError
+	assert.ErrorAs(t, err, &unmatched)
+	assert.Equal(t, []string{"职位", "详情.备注", "部门"}, unmatched.UnknownKeys)
+	assert.Equal(t, []string{"Age", "Detail.Score"}, unmatched.MissingFields)
+	assert.Equal(t, []string{"年龄", "详情.分数"}, unmatched.MissingKeys)
+
+	assert.Nil(t, p.Parse(&StrictObj{}, map[string]any{
+		"姓名": "vogo",
+		"年龄": 1,
+		"详情": map[string]any{"状态": "ok", "分数": 1},
+	}))
+
+	// a field is missing only if no record has its key.
+	list, err := ParseSliceWith[*StrictObj](p, []map[string]any{
+		{"姓名": "a", "部门": "dev"},
+		{"年龄": 1, "职位": "eng"},
+	})
+	assert.Len(t, list, 2)
+	assert.ErrorAs(t, err, &unmatched)
+	assert.Equal(t, []string{"职位", "部门"}, unmatched.UnknownKeys)
+	assert.Equal(t, []string{"Detail"}, unmatched.MissingFields)
+
+	// a key present with a nil value, like an empty cell, is neither unknown nor missing.
+	obj = &StrictObj{}
+	assert.Nil(t, p.Parse(obj, map[string]any{
+		"姓名": nil,
+		"年龄": 1,
+		"详情": map[string]any{"状态": "ok", "分数": nil},
+	}))
+	assert.Equal(t, "", obj.Name)
+	assert.Nil(t, p.Parse(&StrictObj{}, map[string]any{"姓名": "vogo", "年龄": nil, "详情": nil}))
+
+	// parse errors take precedence over unmatched keys.
+	err = p.Parse(&StrictObj{}, map[string]any{"年龄": "x", "部门": "dev"})
+	var fieldErr *FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+}
+
+func TestParseParserTag(t *testing.T) {
+	name, args, err := parseParserTag("timestamp")
+	assert.Nil(t, err)
+	assert.Equal(t, "timestamp", name)
+	assert.Nil(t, args)
+
+	name, args, err = parseParserTag("array_to_string(sep=;)")
+	assert.Nil(t, err)
+	assert.Equal(t, "array_to_string", name)
+	assert.Equal(t, Args{"sep": ";"}, args)
+
+	name, args, err = parseParserTag("timestamp(tz=Asia/Shanghai, strict)")
+	assert.Nil(t, err)
+	assert.Equal(t, "timestamp", name)
+	assert.Equal(t, "Asia/Shanghai", args.Get("tz", ""))
+	assert.Equal(t, "", args.Get("strict", "x"))
+	assert.Equal(t, "x", args.Get("none", "x"))
+
+	_, _, err = parseParserTag("timestamp(tz=UTC")
+	assert.NotNil(t, err)
+
+	// a comma or parenthesis of a value needs quotes.
+	_, args, err = parseParserTag(`array_to_string(sep=', ',wrap="(x)")`)
+	assert.Nil(t, err)
+	assert.Equal(t, Args{"sep": ", ", "wrap": "(x)"}, args)
+
+	for _, tag := range []string{
+		"array_to_string(sep=, )",
+		"array_to_string(sep=)",
+		"array_to_string(sep=;,)",
+		"map_field_text_date(format=Jan 2, 2006)",
+		"array_to_string(=;)",
+		"array_to_string(sep=', )",
+		"array_to_string(sep=', 'x)",
+	} {
+		_, _, err = parseParserTag(tag)
+		assert.NotNil(t, err, tag)
+	}
+
+	type CommaObj struct {
+		Tags string `json:"tags" key:"标签" parser:"array_to_string(sep=', ')"`
+	}
+	obj := &CommaObj{}
+	assert.Nil(t, Parse(obj, map[string]any{"标签": []any{"a", "b"}}))
+	assert.Equal(t, "a, b", obj.Tags)
+
+	type InvalidCommaObj struct {
+		Tags string `json:"tags" key:"标签" parser:"array_to_string(sep=, )"`
+	}
+	assert.ErrorContains(t, Validate[InvalidCommaObj](), "InvalidCommaObj.Tags: invalid parser tag")
+}
+
+type ArgsObj struct {
+	Tags  string `json:"tags" key:"标签" parser:"array_to_string(sep=;)"`
+	Label string `json:"label" key:"名称" parser:"label(prefix=#)"`
+}
+
+func TestParseArgs(t *testing.T) {
+	p := New(WithFieldParser("plain", nil, StringFieldParser))
+	p.SetArgsFieldParser("label", nil, func(dest reflect.Value, val any, args Args) error {
+		dest.SetString(args.Get("prefix", "") + val.(string))
+		return nil
+	})
+
+	obj := &ArgsObj{}
+	assert.Nil(t, p.Parse(obj, map[string]any{"标签": []any{"a", "b"}, "名称": "vogo"}))
+	assert.Equal(t, "a;b", obj.Tags)
+	assert.Equal(t, "#vogo", obj.Label)
+
+	m, err := p.Encode(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, m["标签"])
+}
+
+type DefaultArgsObj struct {
+	Tags  string `json:"tags" key:"标签" parser:"array_to_string"`
+	Other string `json:"other" key:"其他" parser:"array_to_string(sep=|)"`
+	Label string `json:"label" key:"名称" parser:"label"`
+}
+
+func TestParseDefaultArgs(t *testing.T) {
+	p := New(WithParserArgs("array_to_string", Args{"sep": ";"}))
+	p.SetArgsFieldParser("label", nil, func(dest reflect.Value, val any, args Args) error {
+		dest.SetString(args.Get("prefix", "") + val.(string))
+		return nil
+	})
+	p.SetParserArgs("label", Args{"prefix": "@"})
+
+	obj := &DefaultArgsObj{}
+	record := map[string]any{"标签": []any{"a", "b"}, "其他": []any{"a", "b"}, "名称": "vogo"}
+	assert.Nil(t, p.Parse(obj, record))
+	assert.Equal(t, "a;b", obj.Tags)
+	assert.Equal(t, "a|b", obj.Other)
+	assert.Equal(t, "@vogo", obj.Label)
+
+	m, err := p.Encode(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, m["标签"])
+
+	parser, ok := p.FieldParser("array_to_string", reflect.TypeOf(""))
+	assert.True(t, ok)
+	var s string
+	assert.Nil(t, parser(reflect.ValueOf(&s).Elem(), []any{"x", "y"}, nil))
+	assert.Equal(t, "x;y", s)
+
+	_, ok = p.FieldParser("array_to_string", reflect.TypeOf(0))
+	assert.False(t, ok)
+
+	// the default parser is not affected.
+	assert.Nil(t, Default().mergeParserArgs("array_to_string", nil))
+
+	copied := New(WithParsersFrom(p))
+	obj = &DefaultArgsObj{}
+	assert.Nil(t, copied.Parse(obj, record))
+	assert.Equal(t, "@vogo", obj.Label)
+}
+
+// Money is an amount in cents.
+type Money int64
+
+func (m *Money) UnmarshalLarkField(val any) error {
+	f, err := ParseFloatField(val)
+	if err != nil {
+		return err
+	}
+	*m = Money(f*100 + 0.5)
+	return nil
+}
+
+func (m Money) MarshalLarkField() (any, error) {
+	return float64(m) / 100, nil
+}
+
+type Status struct {
+	Code string
+}
+
+func (s *Status) UnmarshalLarkField(val any) error {
+	str, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid status type %T", val)
+	}
+	s.Code = str
+	return nil
+}
+
+type UnmarshalerObj struct {
+	Price    Money   `json:"price" key:"价格"`
+	Discount *Money  `json:"discount" key:"折扣"`
+	Status   Status  `json:"status" key:"状态"`
+	Missing  *Status `json:"missing" key:"缺失"`
+	Text     Money   `json:"text" key:"文本" parser:"int"`
+}
+
+func TestParseUnmarshaler(t *testing.T) {
+	obj := &UnmarshalerObj{}
+	assert.Nil(t, Parse(obj, map[string]any{
+		"价格": 12.34,
+		"折扣": "1.5",
+		"状态": "done",
+		"文本": 7,
+	}))
+	assert.Equal(t, Money(1234), obj.Price)
+	assert.Equal(t, Money(150), *obj.Discount)
+	assert.Equal(t, "done", obj.Status.Code)
+	assert.Nil(t, obj.Missing)
+	// an explicit parser takes precedence.
+	assert.Equal(t, Money(7), obj.Text)
+
+	err := Parse(&UnmarshalerObj{}, map[string]any{"状态": 1})
+	var fieldErr *FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "unmarshaler", fieldErr.Parser)
+
+	m, err := Encode(*obj)
+	assert.Nil(t, err)
+	assert.Equal(t, 12.34, m["价格"])
+	assert.Equal(t, 1.5, m["折扣"])
+	_, hasStatus := m["状态"]
+	assert.False(t, hasStatus)
+}
+
+type InferObj struct {
+	Str      string   `json:"str" key:"文本"`
+	Int      int64    `json:"int" key:"整数"`
+	Uint8    int8     `json:"int8" key:"小整数"`
+	Float    float32  `json:"float" key:"小数"`
+	Custom   []string `json:"custom" key:"自定义"`
+	Explicit string   `json:"explicit" key:"显式" parser:"array_to_string"`
+	Ptr      *int     `json:"ptr" key:"指针"`
+	Bool     bool     `json:"bool" key:"勾选"`
+}
+
+func TestInferParser(t *testing.T) {
+	p := New(WithTypeParser(reflect.TypeOf([]string{}), "string_list"),
+		WithFieldParser("string_list", nil, func(dest reflect.Value, val any) error {
+			for _, v := range val.([]any) {
+				dest.Set(reflect.Append(dest, reflect.ValueOf(v.(string))))
+			}
+			return nil
+		}))
+
+	obj := &InferObj{}
+	assert.Nil(t, p.Parse(obj, map[string]any{
+		"文本":  "s",
+		"整数":  float64(12),
+		"小整数": "3",
+		"小数":  1.5,
+		"自定义": []any{"a", "b"},
+		"显式":  []any{"x", "y"},
+		"指针":  2,
+		"勾选":  "是",
+	}))
+	ptr := 2
+	assert.Equal(t, InferObj{
+		Str: "s", Int: 12, Uint8: 3, Float: 1.5, Custom: []string{"a", "b"}, Explicit: "x,y", Ptr: &ptr, Bool: true,
+	}, *obj)
+
+	// no parser inferred for []string in the default parser.
+	err := Parse(&InferObj{}, map[string]any{})
+	assert.ErrorContains(t, err, "InferObj.Custom: no parser inferred for type []string")
+
+	type Incompatible struct {
+		Count int `json:"count" key:"数量" parser:"string"`
+	}
+	err = Parse(&Incompatible{}, map[string]any{})
+	assert.ErrorContains(t, err, "Incompatible.Count: parser string can not set type int")
+}
+
+type ValidNested struct {
+	Detail *ReviewInfo `json:"detail" key:"详情"`
+}
+
+type InvalidNestedInfo struct {
+	Score int `json:"score" key:"分数" parser:"string"`
+}
+
+type InvalidNested struct {
+	Name   string             `json:"name" key:"姓名"`
+	Detail *InvalidNestedInfo `json:"detail" key:"详情"`
+}
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, Validate[Obj]())
+	assert.Nil(t, Validate[*NestedObj]())
+	assert.Nil(t, Validate[ValidNested]())
+
+	err := Validate[InvalidNested]()
+	var typeErr *FieldTypeError
+	assert.ErrorAs(t, err, &typeErr)
+	assert.Equal(t, "Score", typeErr.Field)
+	assert.Equal(t, "string", typeErr.Parser)
+	assert.Equal(t, reflect.TypeOf(InvalidNestedInfo{}), typeErr.Struct)
+	assert.Equal(t, "maparser.InvalidNestedInfo.Score: parser string can not set type int, expected string", err.Error())
+
+	p := New(WithFieldParser("custom", nil, StringFieldParser), WithFieldTarget("custom", StringTarget))
+	type CustomObj struct {
+		Count int `json:"count" key:"数量" parser:"custom"`
+	}
+	assert.ErrorAs(t, ValidateWith[CustomObj](p), &typeErr)
+	assert.Equal(t, "custom", typeErr.Parser)
+
+	// registering the parser again clears its target.
+	p.SetFieldParser("custom", nil, IntFieldParser)
+	assert.Nil(t, ValidateWith[CustomObj](p))
+
+	assert.NotNil(t, Validate[int]())
+}
+
+func TestParseValue(t *testing.T) {
+	v, err := ParseValue("int", []any{"12"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(12), v)
+
+	v, err = ParseValue("array_to_string", []any{"a", "b"})
+	assert.Nil(t, err)
+	assert.Equal(t, "a,b", v)
+
+	_, err = ParseValue("unknown", 1)
+	assert.NotNil(t, err)
+
+	p := New(WithFieldParser("no_value", nil, StringFieldParser))
+	_, err = p.ParseValue("no_value", "a")
+	assert.NotNil(t, err)
+}
+
+func TestParseMap(t *testing.T) {
+	schema := map[string]string{
+		"姓名": "string",
+		"分数": "float",
+		"标签": "array_to_string",
+		"缺失": "int",
+	}
+
+	m, err := ParseMap(map[string]any{
+		"姓名": "vogo",
+		"分数": "85%",
+		"标签": []any{"a", "b"},
+		"其他": "ignored",
+	}, schema)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"姓名": "vogo", "分数": 0.85, "标签": "a,b"}, m)
+
+	p := New(WithCollectErrors())
+	_, err = p.ParseMap(map[string]any{"姓名": 1, "分数": "x"}, schema)
+	var parseErrs *ParseErrors
+	assert.ErrorAs(t, err, &parseErrs)
+	assert.Len(t, parseErrs.Errors, 2)
+	assert.Equal(t, "分数", parseErrs.Errors[0].Key)
+	assert.Equal(t, "float", parseErrs.Errors[0].Parser)
+}
+
+func TestBoundFieldParser(t *testing.T) {
+	type BoundObj struct {
+		Name string `json:"name" key:"名称" parser:"upper_string"`
+	}
+
+	// upper_string delegates to the string parser of the parser it is bound to.
+	bind := func(p *Parser) (ValueParser, ArgsFieldParser) {
+		return nil, func(dest reflect.Value, val any, args Args) error {
+			parser, ok := p.FieldParser("string", dest.Type())
+			if !ok {
+				return fmt.Errorf("no string parser")
+			}
+			return parser(dest, val, args)
+		}
+	}
+
+	src := New()
+	src.SetBoundFieldParser("upper_string", bind)
+	src.SetAttr("tenant", "a")
+
+	copied := New(WithParsersFrom(src))
+	copied.SetFieldParser("string", nil, func(dest reflect.Value, val any) error {
+		dest.SetString(strings.ToUpper(val.(string)))
+		return nil
+	})
+
+	obj := &BoundObj{}
+	assert.Nil(t, copied.Parse(obj, map[string]any{"名称": "vogo"}))
+	assert.Equal(t, "VOGO", obj.Name)
+
+	obj = &BoundObj{}
+	assert.Nil(t, src.Parse(obj, map[string]any{"名称": "vogo"}))
+	assert.Equal(t, "vogo", obj.Name)
+
+	tenant, ok := copied.Attr("tenant")
+	assert.True(t, ok)
+	assert.Equal(t, "a", tenant)
+
+	// registering a plain parser drops the binder.
+	src.SetFieldParser("upper_string", nil, StringFieldParser)
+	assert.Nil(t, New(WithParsersFrom(src)).Parse(&BoundObj{}, map[string]any{"名称": "vogo"}))
+}
+
+func TestRegisterWhileParsing(t *testing.T) {
+	type RegObj struct {
+		Name string `json:"name" key:"名称" parser:"tenant_string"`
+	}
+
+	setTo := func(s string) FieldParser {
+		return func(dest reflect.Value, _ any) error {
+			dest.SetString(s)
+			return nil
+		}
+	}
+
+	// a config built while the parser is registered again must not stay cached.
+	for i := 0; i < 200; i++ {
+		p := New()
+		p.SetFieldParser("tenant_string", nil, setTo("old"))
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = p.Parse(&RegObj{}, map[string]any{"名称": "vogo"})
+		}()
+		p.SetFieldParser("tenant_string", nil, setTo("new"))
+		wg.Wait()
+
+		obj := &RegObj{}
+		assert.Nil(t, p.Parse(obj, map[string]any{"名称": "vogo"}))
+		if !assert.Equal(t, "new", obj.Name) {
+			return
+		}
+	}
+}
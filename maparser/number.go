@@ -0,0 +1,295 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RoundMode decides how a fractional number is converted to an integer,
+// set by the `round` argument, e.g. `parser:"int(round=half_up)"`.
+type RoundMode string
+
+const (
+	// RoundError fails on a fractional number.
+	RoundError RoundMode = "error"
+	// RoundTrunc drops the fraction, the default of the int parsers.
+	RoundTrunc    RoundMode = "trunc"
+	RoundFloor    RoundMode = "floor"
+	RoundCeil     RoundMode = "ceil"
+	RoundHalfUp   RoundMode = "half_up"
+	RoundHalfEven RoundMode = "half_even"
+)
+
+// ArgsRoundMode returns the round mode of the `round` argument, RoundTrunc by default.
+func ArgsRoundMode(args Args) RoundMode {
+	return RoundMode(args.Get("round", string(RoundTrunc)))
+}
+
+// ValidateRoundArgs checks the `round` argument.
+func ValidateRoundArgs(args Args) error {
+	switch m := ArgsRoundMode(args); m {
+	case RoundError, RoundTrunc, RoundFloor, RoundCeil, RoundHalfUp, RoundHalfEven:
+		return nil
+	default:
+		return fmt.Errorf("invalid round mode %s", m)
+	}
+}
+
+func (m RoundMode) round(f float64) (float64, error) {
+	if f == math.Trunc(f) {
+		return f, nil
+	}
+
+	switch m {
+	case RoundError, "":
+		return 0, fmt.Errorf("fractional number %v", f)
+	case RoundTrunc:
+		return math.Trunc(f), nil
+	case RoundFloor:
+		return math.Floor(f), nil
+	case RoundCeil:
+		return math.Ceil(f), nil
+	case RoundHalfUp:
+		return math.Round(f), nil
+	case RoundHalfEven:
+		return math.RoundToEven(f), nil
+	default:
+		return 0, fmt.Errorf("invalid round mode %s", m)
+	}
+}
+
+type numberKind int
+
+const (
+	numberNone numberKind = iota
+	numberInt
+	numberUint
+	numberFloat
+)
+
+// number is a record value normalized to an integer, an unsigned integer or a float,
+// integers are kept exact instead of going through float64.
+type number struct {
+	kind numberKind
+	i    int64
+	u    uint64
+	f    float64
+}
+
+// thousandsPattern matches numbers with thousand separators, e.g. 1,234,567.89.
+var thousandsPattern = regexp.MustCompile(`^[+-]?\d{1,3}(,\d{3})+(\.\d+)?$`)
+
+// toNumber normalizes a record value of any int, uint or float kind, numeric string or json.Number,
+// taking the first element of an array. Strings may have thousand separators or a % suffix.
+// Absent values, empty strings and arrays result in numberNone.
+func toNumber(val any) (number, error) {
+	if arr, ok := val.([]any); ok {
+		if len(arr) == 0 {
+			return number{}, nil
+		}
+		val = arr[0]
+	}
+
+	if val == nil {
+		return number{}, nil
+	}
+
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return number{kind: numberInt, i: v.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return number{kind: numberUint, u: v.Uint()}, nil
+	case reflect.Float32, reflect.Float64:
+		return number{kind: numberFloat, f: v.Float()}, nil
+	case reflect.String:
+		// json.Number is a string kind as well.
+		return parseNumber(v.String())
+	default:
+		return number{}, fmt.Errorf("invalid number type %T", val)
+	}
+}
+
+func parseNumber(s string) (number, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return number{}, nil
+	}
+
+	if thousandsPattern.MatchString(s) {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil {
+			return number{}, fmt.Errorf("invalid number %s", s)
+		}
+		return number{kind: numberFloat, f: f / 100}, nil
+	}
+
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return number{kind: numberInt, i: i}, nil
+	}
+
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return number{kind: numberUint, u: u}, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return number{}, fmt.Errorf("invalid number %s", s)
+	}
+
+	return number{kind: numberFloat, f: f}, nil
+}
+
+func (n number) int64(mode RoundMode) (int64, error) {
+	switch n.kind {
+	case numberUint:
+		if n.u > math.MaxInt64 {
+			return 0, fmt.Errorf("number %d overflows int64", n.u)
+		}
+		return int64(n.u), nil
+	case numberFloat:
+		f, err := mode.round(n.f)
+		if err != nil {
+			return 0, err
+		}
+		// float64(math.MaxInt64) rounds up to 2^63, which is out of range.
+		if math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, fmt.Errorf("number %v overflows int64", n.f)
+		}
+		return int64(f), nil
+	default:
+		return n.i, nil
+	}
+}
+
+func (n number) uint64(mode RoundMode) (uint64, error) {
+	switch n.kind {
+	case numberInt:
+		if n.i < 0 {
+			return 0, fmt.Errorf("negative number %d for uint", n.i)
+		}
+		return uint64(n.i), nil
+	case numberFloat:
+		f, err := mode.round(n.f)
+		if err != nil {
+			return 0, err
+		}
+		if math.IsNaN(f) || f < 0 || f >= math.MaxUint64 {
+			return 0, fmt.Errorf("number %v overflows uint64", n.f)
+		}
+		return uint64(f), nil
+	default:
+		return n.u, nil
+	}
+}
+
+func (n number) float64() float64 {
+	switch n.kind {
+	case numberInt:
+		return float64(n.i)
+	case numberUint:
+		return float64(n.u)
+	default:
+		return n.f
+	}
+}
+
+// ToInt64 converts a record value to int64, a fractional number is rounded by mode.
+// An absent value converts to 0.
+func ToInt64(val any, mode RoundMode) (int64, error) {
+	n, err := toNumber(val)
+	if err != nil {
+		return 0, err
+	}
+
+	return n.int64(mode)
+}
+
+// ToUint64 converts a record value to uint64, a fractional number is rounded by mode.
+// An absent value converts to 0.
+func ToUint64(val any, mode RoundMode) (uint64, error) {
+	n, err := toNumber(val)
+	if err != nil {
+		return 0, err
+	}
+
+	return n.uint64(mode)
+}
+
+// ToFloat64 converts a record value to float64, an absent value converts to 0.
+func ToFloat64(val any) (float64, error) {
+	n, err := toNumber(val)
+	if err != nil {
+		return 0, err
+	}
+
+	return n.float64(), nil
+}
+
+// SetNumber sets a record value into an int, uint or float field, or a pointer to one,
+// failing on overflow. A fractional number set into an integer field is rounded by mode.
+// An absent value leaves the field untouched.
+func SetNumber(dest reflect.Value, val any, mode RoundMode) error {
+	n, err := toNumber(val)
+	if err != nil || n.kind == numberNone {
+		return err
+	}
+
+	dest = AllocIndirect(dest)
+
+	switch dest.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := n.int64(mode)
+		if err != nil {
+			return err
+		}
+		if dest.OverflowInt(i) {
+			return fmt.Errorf("number %d overflows %s", i, dest.Type())
+		}
+		dest.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := n.uint64(mode)
+		if err != nil {
+			return err
+		}
+		if dest.OverflowUint(u) {
+			return fmt.Errorf("number %d overflows %s", u, dest.Type())
+		}
+		dest.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f := n.float64()
+		if dest.OverflowFloat(f) {
+			return fmt.Errorf("number %v overflows %s", f, dest.Type())
+		}
+		dest.SetFloat(f)
+	default:
+		return fmt.Errorf("invalid number field type %s", dest.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetNumber(t *testing.T) {
+	// every number kind a record value may hold.
+	values := []any{
+		int(7), int8(7), int16(7), int32(7), int64(7),
+		uint(7), uint8(7), uint16(7), uint32(7), uint64(7),
+		float32(7), float64(7),
+		"7", " 7 ", json.Number("7"), []any{float64(7)}, "7.0", "700%",
+	}
+
+	// every field kind a number parser may set.
+	dests := []any{
+		new(int), new(int8), new(int16), new(int32), new(int64),
+		new(uint), new(uint8), new(uint16), new(uint32), new(uint64),
+		new(float32), new(float64),
+		new(*int), new(*uint16), new(*float64),
+	}
+
+	for _, val := range values {
+		for _, dest := range dests {
+			v := reflect.ValueOf(dest).Elem()
+			v.Set(reflect.Zero(v.Type()))
+
+			err := SetNumber(v, val, RoundError)
+			assert.Nil(t, err, "%T(%v) -> %s", val, val, v.Type())
+
+			got := reflect.Indirect(v)
+			if got.Kind() == reflect.Ptr {
+				got = got.Elem()
+			}
+			assert.Equal(t, float64(7), floatOf(got), "%T(%v) -> %s", val, val, v.Type())
+		}
+	}
+}
+
+// floatOf returns the number held by v for comparing fields of any number kind.
+func floatOf(v reflect.Value) float64 {
+	return v.Convert(reflect.TypeOf(float64(0))).Float()
+}
+
+func TestSetNumberCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		dest    any
+		val     any
+		mode    RoundMode
+		want    any
+		wantErr bool
+	}{
+		{name: "nil untouched", dest: new(int), val: nil, want: 0},
+		{name: "empty string untouched", dest: new(int), val: "", want: 0},
+		{name: "empty array untouched", dest: new(int), val: []any{}, want: 0},
+		{name: "nil pointer untouched", dest: new(*int), val: nil, want: (*int)(nil)},
+		{name: "fraction error", dest: new(int), val: 3.7, wantErr: true},
+		{name: "fraction trunc", dest: new(int), val: 3.7, mode: RoundTrunc, want: 3},
+		{name: "fraction floor", dest: new(int), val: -3.2, mode: RoundFloor, want: -4},
+		{name: "fraction ceil", dest: new(int), val: 3.2, mode: RoundCeil, want: 4},
+		{name: "fraction half up", dest: new(int), val: 2.5, mode: RoundHalfUp, want: 3},
+		{name: "fraction half even", dest: new(int), val: 2.5, mode: RoundHalfEven, want: 2},
+		{name: "fraction string", dest: new(int64), val: "3.5", mode: RoundHalfUp, want: int64(4)},
+		{name: "invalid round mode", dest: new(int), val: 3.5, mode: "up", wantErr: true},
+		{name: "thousand separators", dest: new(int64), val: "1,234,567", want: int64(1234567)},
+		{name: "thousand separators float", dest: new(float64), val: "-1,234.5", want: -1234.5},
+		{name: "invalid separators", dest: new(int64), val: "12,34", wantErr: true},
+		{name: "percent", dest: new(float64), val: "12.5%", want: 0.125},
+		{name: "percent int", dest: new(int), val: "300%", want: 3},
+		{name: "json number", dest: new(uint32), val: json.Number("42"), want: uint32(42)},
+		{name: "json number float", dest: new(float32), val: json.Number("1.5"), want: float32(1.5)},
+		{name: "int8 overflow", dest: new(int8), val: 128, wantErr: true},
+		{name: "int8 min", dest: new(int8), val: -128, want: int8(-128)},
+		{name: "uint8 overflow", dest: new(uint8), val: "256", wantErr: true},
+		{name: "negative uint", dest: new(uint), val: -1, wantErr: true},
+		{name: "negative float uint", dest: new(uint), val: -1.0, wantErr: true},
+		{name: "max uint64", dest: new(uint64), val: "18446744073709551615", want: uint64(math.MaxUint64)},
+		{name: "uint64 overflows int64", dest: new(int64), val: uint64(math.MaxUint64), wantErr: true},
+		{name: "max int64 exact", dest: new(int64), val: "9223372036854775807", want: int64(math.MaxInt64)},
+		{name: "float overflows int64", dest: new(int64), val: 1e19, wantErr: true},
+		{name: "float32 overflow", dest: new(float32), val: 1e39, wantErr: true},
+		{name: "nan", dest: new(int), val: math.NaN(), mode: RoundTrunc, wantErr: true},
+		{name: "invalid string", dest: new(int), val: "abc", wantErr: true},
+		{name: "invalid type", dest: new(int), val: true, wantErr: true},
+		{name: "invalid dest", dest: new(string), val: 1, wantErr: true},
+		{name: "pointer", dest: new(*int), val: 5, want: intPtr(5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := reflect.ValueOf(tt.dest).Elem()
+			err := SetNumber(v, tt.val, tt.mode)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, v.Interface())
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestNumberValueParsers(t *testing.T) {
+	tests := []struct {
+		name    string
+		parser  ValueParser
+		val     any
+		want    any
+		wantErr bool
+	}{
+		{name: "int nil", parser: IntValueParser, val: nil, want: nil},
+		{name: "int array", parser: IntValueParser, val: []any{"12"}, want: int64(12)},
+		{name: "int uint", parser: IntValueParser, val: uint16(3), want: int64(3)},
+		{name: "int fraction", parser: IntValueParser, val: 3.7, want: int64(3)},
+		{name: "int large string", parser: IntValueParser, val: "9007199254740993", want: int64(9007199254740993)},
+		{name: "float array", parser: FloatValueParser, val: []any{"1,000.5"}, want: 1000.5},
+		{name: "float percent", parser: FloatValueParser, val: "50%", want: 0.5},
+		{name: "float json number", parser: FloatValueParser, val: json.Number("2.5"), want: 2.5},
+		{name: "float invalid", parser: FloatValueParser, val: map[string]any{}, wantErr: true},
+		{name: "array first int64", parser: ArrayFirstInt64ValueParser, val: []string{"5"}, want: int64(5)},
+		{name: "array first int64 fraction", parser: ArrayFirstInt64ValueParser, val: []any{3.7}, want: int64(3)},
+		{name: "array first int64 nil", parser: ArrayFirstInt64ValueParser, val: nil, want: int64(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parser(tt.val)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseIntFieldTruncates(t *testing.T) {
+	i, err := ParseIntField(3.7)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), i)
+
+	i, err = ParseIntField(-3.7)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-3), i)
+}
+
+type RoundObj struct {
+	Count uint16 `json:"count" key:"数量" parser:"int(round=half_up)"`
+	Trunc int    `json:"trunc" key:"截断" parser:"int(round=trunc)"`
+	Exact int    `json:"exact" key:"精确" parser:"int(round=error)"`
+	Plain int    `json:"plain" key:"默认"`
+	First int    `json:"first" key:"首个" parser:"array_first_int64(round=ceil)"`
+}
+
+func TestParseRoundArgs(t *testing.T) {
+	obj := &RoundObj{}
+	assert.Nil(t, Parse(obj, map[string]any{"数量": 2.5, "截断": "9.9"}))
+	assert.Equal(t, uint16(3), obj.Count)
+	assert.Equal(t, 9, obj.Trunc)
+
+	// fractions are truncated unless the `round` argument is given.
+	obj = &RoundObj{}
+	assert.Nil(t, Parse(obj, map[string]any{"默认": -3.7, "首个": []any{1.2, 5.0}}))
+	assert.Equal(t, -3, obj.Plain)
+	assert.Equal(t, 2, obj.First)
+	v, err := ParseValue("int", 3.7)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), v)
+
+	assert.NotNil(t, Parse(&RoundObj{}, map[string]any{"精确": 1.5}))
+
+	type InvalidRoundObj struct {
+		Count int `json:"count" key:"数量" parser:"int(round=up)"`
+	}
+	assert.ErrorContains(t, Validate[InvalidRoundObj](), "maparser.InvalidRoundObj.Count: parser int: invalid round mode up")
+
+	// the validator is copied, and dropped by registering the parser again.
+	p := New(WithParsersFrom(Default()))
+	assert.NotNil(t, ValidateWith[InvalidRoundObj](p))
+	p.SetFieldParser("int", nil, IntFieldParser)
+	assert.Nil(t, ValidateWith[InvalidRoundObj](p))
+	p = New(WithArgsValidator("int", func(Args) error { return fmt.Errorf("rejected") }))
+	assert.ErrorContains(t, ValidateWith[RoundObj](p), "rejected")
+}
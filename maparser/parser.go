@@ -0,0 +1,570 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Parser owns a registry of field parsers and encoders together with the field configs
+// of the struct types it has seen. Parsers are independent of each other, so the same
+// parser name can be registered differently per table or per tenant.
+type Parser struct {
+	// lock guards the registries, which are written at registration
+	// and read whenever a type config is built.
+	lock           sync.RWMutex
+	fieldParserMap map[string]ArgsFieldParser
+	valueParserMap map[string]ValueParser
+	fieldEncoders  map[string]ArgsFieldEncoder
+
+	// typeParserMap maps a field type to the parser inferred for fields without parser tag.
+	typeParserMap map[reflect.Type]string
+
+	// fieldTargets are the field types a parser can set, checked when a type config is built.
+	fieldTargets map[string]FieldTarget
+
+	// parserArgs are the default arguments of a parser, overridden by the arguments of the parser tag.
+	parserArgs map[string]Args
+
+	// argsValidators check the arguments of a parser, when a type config is built.
+	argsValidators map[string]ArgsValidator
+
+	// validatorBinders build the validators of argsValidators bound to the parser, see SetBoundArgsValidator.
+	validatorBinders map[string]ValidatorBinder
+
+	// parserBinders and encoderBinders build the parsers depending on the registry,
+	// which are bound again when copied into another parser.
+	parserBinders  map[string]ParserBinder
+	encoderBinders map[string]EncoderBinder
+
+	// attrs are the attributes set by SetAttr.
+	attrs map[any]any
+
+	// collectErrors parses every field of a record and returns all failures as *ParseErrors.
+	collectErrors bool
+
+	// strict reports record keys claimed by no field and fields whose key is not found.
+	strict bool
+
+	// foldKeys matches record keys ignoring case and whitespace.
+	foldKeys bool
+
+	// keyMatchHook is called with the field name and the record key matched for it.
+	keyMatchHook func(field, key string)
+
+	// typeConfigs caches the field configs of a struct type, reflect.Type -> []fieldConfig.
+	typeConfigs sync.Map
+
+	// generation counts the registrations, a config built from an older generation is not cached.
+	generation uint64
+}
+
+type Option func(p *Parser)
+
+// WithFieldParser registers a field parser into the new parser.
+func WithFieldParser(name string, valueParser ValueParser, fieldParser FieldParser) Option {
+	return func(p *Parser) {
+		p.SetFieldParser(name, valueParser, fieldParser)
+	}
+}
+
+// WithFieldEncoder registers a field encoder into the new parser.
+func WithFieldEncoder(name string, encoder FieldEncoder) Option {
+	return func(p *Parser) {
+		p.SetFieldEncoder(name, encoder)
+	}
+}
+
+// WithTypeParser sets the parser inferred for fields of type t into the new parser.
+func WithTypeParser(t reflect.Type, parserName string) Option {
+	return func(p *Parser) {
+		p.SetTypeParser(t, parserName)
+	}
+}
+
+// WithFieldTarget declares the field types the parser of name can set in the new parser.
+func WithFieldTarget(name string, target FieldTarget) Option {
+	return func(p *Parser) {
+		p.SetFieldTarget(name, target)
+	}
+}
+
+// WithParserArgs sets the default arguments of the parser of name in the new parser,
+// e.g. WithParserArgs("timestamp", Args{"tz": "Asia/Shanghai"}).
+func WithParserArgs(name string, args Args) Option {
+	return func(p *Parser) {
+		p.SetParserArgs(name, args)
+	}
+}
+
+// WithArgsValidator sets the validator of the arguments of the parser of name in the new parser.
+func WithArgsValidator(name string, validator ArgsValidator) Option {
+	return func(p *Parser) {
+		p.SetArgsValidator(name, validator)
+	}
+}
+
+// WithCollectErrors makes Parse continue after a failed field and return every
+// failure of the record as a *ParseErrors, instead of the first *FieldError.
+func WithCollectErrors() Option {
+	return func(p *Parser) {
+		p.collectErrors = true
+	}
+}
+
+// WithStrict makes Parse report keys of the record claimed by no field, and fields whose key
+// is not found in the record, as an *UnmatchedError. ParseSlice reports the fields whose key
+// is found in no record. The unmatched error is only returned when the parsing succeeds,
+// the destination is populated either way.
+func WithStrict() Option {
+	return func(p *Parser) {
+		p.strict = true
+	}
+}
+
+// WithFoldKeys matches record keys ignoring case and whitespace,
+// e.g. the key tag "Email" matches the record key " email", and "员工姓名" matches "员工 姓名".
+func WithFoldKeys() Option {
+	return func(p *Parser) {
+		p.foldKeys = true
+	}
+}
+
+// WithKeyMatchHook sets a debug hook called with the field name and the record key matched for it,
+// which tells which alternative key of a renamed column is in use.
+func WithKeyMatchHook(hook func(field, key string)) Option {
+	return func(p *Parser) {
+		p.keyMatchHook = hook
+	}
+}
+
+// WithParsersFrom copies the parsers and encoders registered in src into the new parser,
+// binding the parsers registered with SetBoundFieldParser to the new parser,
+// e.g. WithParsersFrom(Default()) to start from the parsers registered by imported packages.
+func WithParsersFrom(src *Parser) Option {
+	return func(p *Parser) {
+		src.lock.RLock()
+		defer src.lock.RUnlock()
+
+		// attributes first, so that the bound parsers find them.
+		for key, val := range src.attrs {
+			p.SetAttr(key, val)
+		}
+		for name, fieldParser := range src.fieldParserMap {
+			if bind, ok := src.parserBinders[name]; ok {
+				p.SetBoundFieldParser(name, bind)
+				continue
+			}
+			p.SetArgsFieldParser(name, src.valueParserMap[name], fieldParser)
+		}
+		for name, encoder := range src.fieldEncoders {
+			if bind, ok := src.encoderBinders[name]; ok {
+				p.SetBoundFieldEncoder(name, bind)
+				continue
+			}
+			p.SetArgsFieldEncoder(name, encoder)
+		}
+		for t, name := range src.typeParserMap {
+			p.SetTypeParser(t, name)
+		}
+		for name, target := range src.fieldTargets {
+			p.SetFieldTarget(name, target)
+		}
+		for name, args := range src.parserArgs {
+			p.SetParserArgs(name, args)
+		}
+		for name, validator := range src.argsValidators {
+			if bind, ok := src.validatorBinders[name]; ok {
+				p.SetBoundArgsValidator(name, bind)
+				continue
+			}
+			p.SetArgsValidator(name, validator)
+		}
+	}
+}
+
+// New creates a parser with the builtin parsers registered, options are applied in order.
+func New(opts ...Option) *Parser {
+	p := &Parser{
+		fieldParserMap:   map[string]ArgsFieldParser{},
+		valueParserMap:   map[string]ValueParser{},
+		fieldEncoders:    map[string]ArgsFieldEncoder{},
+		typeParserMap:    map[reflect.Type]string{},
+		fieldTargets:     map[string]FieldTarget{},
+		parserArgs:       map[string]Args{},
+		argsValidators:   map[string]ArgsValidator{},
+		validatorBinders: map[string]ValidatorBinder{},
+		parserBinders:    map[string]ParserBinder{},
+		encoderBinders:   map[string]EncoderBinder{},
+		attrs:            map[any]any{},
+	}
+
+	registerBuiltinParsers(p)
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Parser) SetFieldParser(name string, valueParser ValueParser, fieldParser FieldParser) {
+	p.SetArgsFieldParser(name, valueParser, withoutArgsParser(fieldParser))
+}
+
+// SetArgsFieldParser registers a field parser receiving the arguments of the parser tag.
+func (p *Parser) SetArgsFieldParser(name string, valueParser ValueParser, fieldParser ArgsFieldParser) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.valueParserMap[name] = valueParser
+	p.fieldParserMap[name] = fieldParser
+	delete(p.fieldTargets, name)
+	delete(p.argsValidators, name)
+	delete(p.validatorBinders, name)
+	delete(p.parserBinders, name)
+
+	// cached configs hold the previous parser functions.
+	p.clearTypeConfigs()
+}
+
+func (p *Parser) SetFieldEncoder(name string, encoder FieldEncoder) {
+	p.SetArgsFieldEncoder(name, withoutArgsEncoder(encoder))
+}
+
+// SetArgsFieldEncoder registers a field encoder receiving the arguments of the parser tag.
+func (p *Parser) SetArgsFieldEncoder(name string, encoder ArgsFieldEncoder) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.fieldEncoders[name] = encoder
+	delete(p.encoderBinders, name)
+
+	// cached configs hold the previous encoder functions.
+	p.clearTypeConfigs()
+}
+
+// SetTypeParser sets the parser inferred for fields of type t without parser tag,
+// e.g. SetTypeParser(reflect.TypeOf(time.Time{}), "timestamp").
+func (p *Parser) SetTypeParser(t reflect.Type, parserName string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.typeParserMap[t] = parserName
+	p.clearTypeConfigs()
+}
+
+func (p *Parser) getTypeParser(t reflect.Type) (string, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	name, ok := p.typeParserMap[t]
+	return name, ok
+}
+
+// SetFieldTarget declares the field types the parser of name can set, fields of other types
+// fail when the type config is built. Registering the parser again clears its target.
+func (p *Parser) SetFieldTarget(name string, target FieldTarget) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.fieldTargets[name] = target
+	p.clearTypeConfigs()
+}
+
+func (p *Parser) getFieldTarget(name string) (FieldTarget, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	target, ok := p.fieldTargets[name]
+	return target, ok
+}
+
+// SetParserArgs sets the default arguments of the parser of name, the arguments of a parser tag
+// override them one by one.
+func (p *Parser) SetParserArgs(name string, args Args) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	copied := make(Args, len(args))
+	for k, v := range args {
+		copied[k] = v
+	}
+
+	p.parserArgs[name] = copied
+	p.clearTypeConfigs()
+}
+
+// SetArgsValidator sets the validator of the arguments of the parser of name, a field whose
+// arguments it rejects fails when the type config is built. Registering the parser again clears it.
+func (p *Parser) SetArgsValidator(name string, validator ArgsValidator) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.argsValidators[name] = validator
+	delete(p.validatorBinders, name)
+	p.clearTypeConfigs()
+}
+
+func (p *Parser) getArgsValidator(name string) (ArgsValidator, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	validator, ok := p.argsValidators[name]
+	return validator, ok
+}
+
+// mergeParserArgs returns the default arguments of the parser of name overridden by args.
+func (p *Parser) mergeParserArgs(name string, args Args) Args {
+	p.lock.RLock()
+	defaults := p.parserArgs[name]
+	p.lock.RUnlock()
+
+	if len(defaults) == 0 {
+		return args
+	}
+
+	merged := make(Args, len(defaults)+len(args))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range args {
+		merged[k] = v
+	}
+
+	return merged
+}
+
+func (p *Parser) getFieldParser(name string) (ArgsFieldParser, ArgsFieldEncoder, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	parser, ok := p.fieldParserMap[name]
+	return parser, p.fieldEncoders[name], ok
+}
+
+// FieldParser returns the field parser of name if it can set fields of type t,
+// for parsers delegating to other registered parsers. The default arguments of the parser
+// are applied to the arguments it is called with.
+func (p *Parser) FieldParser(name string, t reflect.Type) (ArgsFieldParser, bool) {
+	parser, _, ok := p.getFieldParser(name)
+	if !ok {
+		return nil, false
+	}
+
+	if target, targetOk := p.getFieldTarget(name); targetOk && !target.Accepts(t) {
+		return nil, false
+	}
+
+	return func(dest reflect.Value, val any, args Args) error {
+		return parser(dest, val, p.mergeParserArgs(name, args))
+	}, true
+}
+
+// InferParser returns the name of the parser inferred for fields of type t without parser tag.
+func (p *Parser) InferParser(t reflect.Type) (string, bool) {
+	return p.inferParserName(t)
+}
+
+// Parse parses m into dest, a pointer to struct. A failed field is reported as a *FieldError,
+// or all failed fields as a *ParseErrors if the parser is created with WithCollectErrors.
+// A strict parser reports unmatched keys and fields as an *UnmatchedError after a successful parsing.
+func (p *Parser) Parse(dest any, m map[string]any) error {
+	state, err := p.parse(dest, m)
+	if err != nil {
+		return err
+	}
+
+	return state.unmatchedError()
+}
+
+func (p *Parser) parse(dest any, m map[string]any) (state *parseState, perr error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			perr = fmt.Errorf("parse error: %v", panicErr)
+		}
+	}()
+
+	state = &parseState{strict: p.strict}
+
+	destValue := reflect.ValueOf(dest)
+	for destValue.Kind() == reflect.Ptr {
+		destValue = destValue.Elem()
+	}
+
+	if err := p.parseStruct(destValue, m, "", "", state); err != nil {
+		return state, err
+	}
+
+	if len(state.errs) > 0 {
+		return state, &ParseErrors{Errors: state.errs}
+	}
+
+	return state, nil
+}
+
+// parseStruct parses m into the struct value v, field errors are collected into state when collecting errors,
+// otherwise the first one is returned. namePrefix and keyPrefix qualify the fields of a nested struct in errors.
+func (p *Parser) parseStruct(v reflect.Value, m map[string]any, namePrefix, keyPrefix string, state *parseState) error {
+	configs, err := p.getTypeFieldConfigs(v.Type())
+	if err != nil {
+		return err
+	}
+
+	var foldedKeys map[string]string
+	if p.foldKeys {
+		foldedKeys = make(map[string]string, len(m))
+		for key := range m {
+			foldedKeys[foldKey(key)] = key
+		}
+	}
+
+	for _, config := range configs {
+		key, val := p.lookupValue(m, foldedKeys, config)
+		if state.strict {
+			state.match(namePrefix+config.Name, keyPrefix+config.Key, keyPrefix, key)
+		}
+		if key != "" && p.keyMatchHook != nil {
+			p.keyMatchHook(namePrefix+config.Name, key)
+		}
+		if key == "" {
+			key = config.Key
+		}
+
+		val, parseErr := p.parseField(v, config, val, namePrefix, keyPrefix+key, state)
+		if parseErr == nil {
+			continue
+		}
+
+		if _, isFieldErr := parseErr.(*FieldError); isFieldErr {
+			// a field error of the nested struct, not collecting errors.
+			return parseErr
+		}
+
+		fieldErr := &FieldError{
+			Field:  namePrefix + config.Name,
+			Key:    keyPrefix + key,
+			Parser: config.ParserName,
+			Value:  val,
+			Err:    parseErr,
+		}
+
+		if !p.collectErrors {
+			return fieldErr
+		}
+
+		state.errs = append(state.errs, fieldErr)
+	}
+
+	if state.strict {
+		state.unknown(m, keyPrefix)
+	}
+
+	return nil
+}
+
+// lookupValue returns the first alternative key of the field found in m with its value,
+// foldedKeys maps folded keys to the keys of m when folding keys.
+// A key present with a nil value is returned if no key has a value, so that strict parsers count it as claimed.
+func (p *Parser) lookupValue(m map[string]any, foldedKeys map[string]string, config fieldConfig) (string, any) {
+	nilKey := ""
+	for _, key := range config.Keys {
+		val, ok := m[key]
+		if ok && val != nil {
+			return key, val
+		}
+		if ok && nilKey == "" {
+			nilKey = key
+		}
+	}
+
+	if foldedKeys != nil {
+		for _, key := range config.FoldedKeys {
+			matched, ok := foldedKeys[key]
+			if ok && m[matched] != nil {
+				return matched, m[matched]
+			}
+			if ok && nilKey == "" {
+				nilKey = matched
+			}
+		}
+	}
+
+	return nilKey, nil
+}
+
+// parseField parses val into the field of config, fieldKey is the qualified key of the field in errors.
+// It returns the value parsed, which is the default of the field in place of an empty val.
+func (p *Parser) parseField(v reflect.Value, config fieldConfig, val any, namePrefix, fieldKey string, state *parseState) (any, error) {
+	if isEmptyValue(val) {
+		if config.Required {
+			return val, ErrRequired
+		}
+
+		if config.HasDefault {
+			val = config.Default
+		}
+	}
+
+	if config.Nested && val == nil {
+		return val, nil
+	}
+
+	// pointers to flattened structs are only allocated when there is a value to parse.
+	field, ok := fieldByIndex(v, config.Index, val != nil)
+	if !ok {
+		return val, nil
+	}
+
+	if config.Nested {
+		return val, p.parseNested(field, val, namePrefix+config.Name+".", fieldKey+".", state)
+	}
+
+	return val, callFieldParser(config.Parser, field, val, config.Args)
+}
+
+// parseNested parses a nested map value into a struct or pointer to struct field.
+func (p *Parser) parseNested(field reflect.Value, val any, namePrefix, keyPrefix string, state *parseState) error {
+	m, ok := val.(map[string]any)
+	if !ok {
+		return fmt.Errorf("invalid nested type %T", val)
+	}
+
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+
+	return p.parseStruct(field, m, namePrefix, keyPrefix, state)
+}
+
+// callFieldParser converts a panic of the parser, e.g. setting a value of a wrong kind, into an error,
+// so that the remaining fields are still parsed when collecting errors.
+func callFieldParser(parser ArgsFieldParser, dest reflect.Value, val any, args Args) (err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = fmt.Errorf("%v", panicErr)
+		}
+	}()
+
+	return parser(dest, val, args)
+}
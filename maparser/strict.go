@@ -0,0 +1,119 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import "sort"
+
+// parseState carries the field errors and, for strict parsers, the matched keys of one parsing.
+type parseState struct {
+	errs []*FieldError
+
+	strict        bool
+	claimedKeys   map[string]bool
+	unknownKeys   []string
+	missingFields []string
+	missingKeys   []string
+}
+
+// match records the key found for a field, an empty key means the field is missing.
+// Keys are qualified with the key prefix of nested maps.
+func (s *parseState) match(fieldName, fieldKey, keyPrefix, key string) {
+	if key == "" {
+		s.missingFields = append(s.missingFields, fieldName)
+		s.missingKeys = append(s.missingKeys, fieldKey)
+		return
+	}
+
+	if s.claimedKeys == nil {
+		s.claimedKeys = map[string]bool{}
+	}
+	s.claimedKeys[keyPrefix+key] = true
+}
+
+// unknown records the keys of m claimed by no field.
+func (s *parseState) unknown(m map[string]any, keyPrefix string) {
+	for key := range m {
+		if !s.claimedKeys[keyPrefix+key] {
+			s.unknownKeys = append(s.unknownKeys, keyPrefix+key)
+		}
+	}
+}
+
+func (s *parseState) unmatchedError() error {
+	if !s.strict || (len(s.unknownKeys) == 0 && len(s.missingFields) == 0) {
+		return nil
+	}
+
+	sort.Strings(s.unknownKeys)
+
+	return &UnmatchedError{
+		UnknownKeys:   s.unknownKeys,
+		MissingFields: s.missingFields,
+		MissingKeys:   s.missingKeys,
+	}
+}
+
+// unmatchedRecords aggregates the strict states of several records, the unknown keys of all records,
+// and the fields missing from every record.
+type unmatchedRecords struct {
+	records       int
+	unknownKeys   map[string]bool
+	missingCounts map[string]int
+	first         *parseState
+}
+
+func (u *unmatchedRecords) add(s *parseState) {
+	if !s.strict {
+		return
+	}
+
+	u.records++
+	if u.first == nil {
+		u.first = s
+		u.unknownKeys = map[string]bool{}
+		u.missingCounts = map[string]int{}
+	}
+
+	for _, key := range s.unknownKeys {
+		u.unknownKeys[key] = true
+	}
+	for _, name := range s.missingFields {
+		u.missingCounts[name]++
+	}
+}
+
+func (u *unmatchedRecords) unmatchedError() error {
+	if u.first == nil {
+		return nil
+	}
+
+	s := &parseState{strict: true}
+	for key := range u.unknownKeys {
+		s.unknownKeys = append(s.unknownKeys, key)
+	}
+
+	// a field missing from every record is missing from the first one, which keeps the declaration order.
+	for i, name := range u.first.missingFields {
+		if u.missingCounts[name] == u.records {
+			s.missingFields = append(s.missingFields, name)
+			s.missingKeys = append(s.missingKeys, u.first.missingKeys[i])
+		}
+	}
+
+	return s.unmatchedError()
+}
@@ -0,0 +1,129 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FieldTarget declares the field types a parser can set. A field matches if its type, or the
+// type it points to, is one of Types or is of one of Kinds.
+type FieldTarget struct {
+	Kinds []reflect.Kind
+	Types []reflect.Type
+}
+
+var (
+	StringTarget = FieldTarget{Kinds: []reflect.Kind{reflect.String}}
+	IntTarget    = FieldTarget{Kinds: []reflect.Kind{
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+	}}
+	FloatTarget = FieldTarget{Kinds: []reflect.Kind{reflect.Float32, reflect.Float64}}
+)
+
+// TypesTarget returns a target of the given types.
+func TypesTarget(types ...reflect.Type) FieldTarget {
+	return FieldTarget{Types: types}
+}
+
+// Accepts reports whether a field of type t matches the target.
+func (f FieldTarget) Accepts(t reflect.Type) bool {
+	if slices.Contains(f.Types, t) {
+		return true
+	}
+
+	t = indirectType(t)
+
+	return slices.Contains(f.Types, t) || slices.Contains(f.Kinds, t.Kind())
+}
+
+func (f FieldTarget) String() string {
+	names := make([]string, 0, len(f.Kinds)+len(f.Types))
+	for _, kind := range f.Kinds {
+		names = append(names, kind.String())
+	}
+	for _, t := range f.Types {
+		names = append(names, t.String())
+	}
+
+	return strings.Join(names, "|")
+}
+
+// FieldTypeError reports a parser used on a field of a type it can not set.
+type FieldTypeError struct {
+	Struct   reflect.Type
+	Field    string
+	Parser   string
+	Type     reflect.Type
+	Expected FieldTarget
+}
+
+func (e *FieldTypeError) Error() string {
+	return fmt.Sprintf("%s.%s: parser %s can not set type %s, expected %s",
+		e.Struct, e.Field, e.Parser, e.Type, e.Expected)
+}
+
+// SetFieldTarget declares the field types the parser of name can set in the default parser.
+func SetFieldTarget(name string, target FieldTarget) {
+	defaultParser.SetFieldTarget(name, target)
+}
+
+// Validate builds the field configs of T and of its nested structs with the default parser,
+// reporting unknown parsers, invalid tags and *FieldTypeError, for use in tests and at startup.
+func Validate[T any]() error {
+	return ValidateWith[T](defaultParser)
+}
+
+// ValidateWith is Validate using parser p.
+func ValidateWith[T any](p *Parser) error {
+	t, ok := structTypeOf(indirectType(reflect.TypeOf((*T)(nil)).Elem()))
+	if !ok {
+		return fmt.Errorf("invalid type %s, struct required", t)
+	}
+
+	return p.validateType(t, map[reflect.Type]bool{})
+}
+
+func (p *Parser) validateType(t reflect.Type, visited map[reflect.Type]bool) error {
+	if visited[t] {
+		return nil
+	}
+	visited[t] = true
+
+	configs, err := p.getTypeFieldConfigs(t)
+	if err != nil {
+		return err
+	}
+
+	for _, config := range configs {
+		if !config.Nested {
+			continue
+		}
+
+		structType, _ := structTypeOf(config.Type)
+		if err = p.validateType(structType, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
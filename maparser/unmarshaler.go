@@ -0,0 +1,84 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import "reflect"
+
+// FieldUnmarshaler is implemented by types parsing themselves from a record value,
+// it is used for fields without a parser tag. It is not called for absent values.
+type FieldUnmarshaler interface {
+	UnmarshalLarkField(val any) error
+}
+
+// FieldMarshaler is implemented by types encoding themselves into a record value,
+// the reverse of FieldUnmarshaler. A nil result leaves the field out of the encoded map.
+type FieldMarshaler interface {
+	MarshalLarkField() (any, error)
+}
+
+// unmarshalerParserName is the parser name of fields parsed by FieldUnmarshaler in errors.
+const unmarshalerParserName = "unmarshaler"
+
+var (
+	fieldUnmarshalerType = reflect.TypeOf((*FieldUnmarshaler)(nil)).Elem()
+	fieldMarshalerType   = reflect.TypeOf((*FieldMarshaler)(nil)).Elem()
+)
+
+// implementsPtr reports whether t or a pointer to t implements the interface.
+func implementsPtr(t, iface reflect.Type) bool {
+	return t.Implements(iface) || (t.Kind() != reflect.Ptr && reflect.PointerTo(t).Implements(iface))
+}
+
+// methodReceiver returns v or its address as the receiver of the interface methods,
+// nil pointers are allocated.
+func methodReceiver(v reflect.Value, iface reflect.Type) reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return v
+	}
+
+	if v.Type().Implements(iface) {
+		return v
+	}
+
+	if !v.CanAddr() {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr
+	}
+
+	return v.Addr()
+}
+
+func unmarshalerFieldParser(dest reflect.Value, val any, _ Args) error {
+	if val == nil {
+		return nil
+	}
+
+	return methodReceiver(dest, fieldUnmarshalerType).Interface().(FieldUnmarshaler).UnmarshalLarkField(val)
+}
+
+func marshalerFieldEncoder(src reflect.Value, _ Args) (any, error) {
+	if src.Kind() == reflect.Ptr && src.IsNil() {
+		return nil, nil
+	}
+
+	return methodReceiver(src, fieldMarshalerType).Interface().(FieldMarshaler).MarshalLarkField()
+}
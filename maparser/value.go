@@ -0,0 +1,107 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maparser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ValueParserOf adapts a typed parse function into a ValueParser.
+func ValueParserOf[T any](parse func(val any) (T, error)) ValueParser {
+	return func(val any) (any, error) {
+		return parse(val)
+	}
+}
+
+// ParseValue converts a raw record value by the value parser of parserName in the default parser.
+func ParseValue(parserName string, val any) (any, error) {
+	return defaultParser.ParseValue(parserName, val)
+}
+
+// ParseMap converts a raw record into normalized values by a column -> parser name schema
+// in the default parser.
+func ParseMap(m map[string]any, schema map[string]string) (map[string]any, error) {
+	return defaultParser.ParseMap(m, schema)
+}
+
+func (p *Parser) getValueParser(name string) (ValueParser, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	parser, ok := p.valueParserMap[name]
+	return parser, ok && parser != nil
+}
+
+// ParseValue converts a raw record value by the value parser of parserName,
+// for dynamic use cases without a struct.
+func (p *Parser) ParseValue(parserName string, val any) (result any, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = fmt.Errorf("parse error: %v", panicErr)
+		}
+	}()
+
+	parser, ok := p.getValueParser(parserName)
+	if !ok {
+		return nil, fmt.Errorf("invalid value parser %s", parserName)
+	}
+
+	return parser(val)
+}
+
+// ParseMap converts the columns of the schema, column -> parser name, of a raw record into
+// normalized values. Columns absent from m are left out. A failed column is reported as a
+// *FieldError, or all failed columns as a *ParseErrors if the parser is created with WithCollectErrors.
+func (p *Parser) ParseMap(m map[string]any, schema map[string]string) (map[string]any, error) {
+	columns := make([]string, 0, len(schema))
+	for column := range schema {
+		columns = append(columns, column)
+	}
+	// sorted for deterministic errors.
+	sort.Strings(columns)
+
+	result := make(map[string]any, len(schema))
+	var errs []*FieldError
+
+	for _, column := range columns {
+		val, ok := m[column]
+		if !ok {
+			continue
+		}
+
+		parserName := schema[column]
+		v, err := p.ParseValue(parserName, val)
+		if err != nil {
+			fieldErr := &FieldError{Field: column, Key: column, Parser: parserName, Value: val, Err: err}
+			if !p.collectErrors {
+				return nil, fieldErr
+			}
+			errs = append(errs, fieldErr)
+			continue
+		}
+
+		result[column] = v
+	}
+
+	if len(errs) > 0 {
+		return nil, &ParseErrors{Errors: errs}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,239 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+// Link is the value of a url field.
+type Link struct {
+	Text string `json:"text"`
+	Link string `json:"link"`
+}
+
+// ParseLink parses a url field, a plain string is used as both text and link.
+func ParseLink(val any) (*Link, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		return &Link{Text: v, Link: v}, nil
+	case map[string]any:
+		return &Link{Text: mapString(v, "text"), Link: mapString(v, "link")}, nil
+	case []any:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		return ParseLink(v[0])
+	default:
+		return nil, fmt.Errorf("ParseLink: invalid type %T", val)
+	}
+}
+
+// LinkFieldParser sets a Link field, or the link into a string field.
+func LinkFieldParser(dest reflect.Value, val any) error {
+	link, err := ParseLink(val)
+	if err != nil || link == nil {
+		return err
+	}
+
+	if v := maparser.AllocIndirect(dest); v.Kind() == reflect.String {
+		v.SetString(link.Link)
+		return nil
+	}
+
+	maparser.SetValue(dest, *link)
+	return nil
+}
+
+func LinkFieldEncoder(src reflect.Value) (any, error) {
+	val := maparser.GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	var link Link
+	switch v := val.(type) {
+	case Link:
+		link = v
+	case string:
+		link = Link{Text: v, Link: v}
+	default:
+		return nil, fmt.Errorf("LinkFieldEncoder: invalid type %T", val)
+	}
+
+	if link.Link == "" {
+		return nil, nil
+	}
+
+	return map[string]any{"text": link.Text, "link": link.Link}, nil
+}
+
+// Location is the value of a location field.
+type Location struct {
+	// Location is the longitude and latitude, e.g. "116.397755,39.903179".
+	Location    string `json:"location"`
+	Province    string `json:"pname"`
+	City        string `json:"cityname"`
+	District    string `json:"adname"`
+	Address     string `json:"address"`
+	Name        string `json:"name"`
+	FullAddress string `json:"full_address"`
+}
+
+// LngLat returns the longitude and latitude of the location.
+func (l *Location) LngLat() (lng, lat float64, err error) {
+	lngStr, latStr, ok := strings.Cut(l.Location, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid location %s", l.Location)
+	}
+
+	if lng, err = strconv.ParseFloat(strings.TrimSpace(lngStr), 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid location %s", l.Location)
+	}
+
+	if lat, err = strconv.ParseFloat(strings.TrimSpace(latStr), 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid location %s", l.Location)
+	}
+
+	return lng, lat, nil
+}
+
+func ParseLocation(val any) (*Location, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return &Location{
+			Location:    mapString(v, "location"),
+			Province:    mapString(v, "pname"),
+			City:        mapString(v, "cityname"),
+			District:    mapString(v, "adname"),
+			Address:     mapString(v, "address"),
+			Name:        mapString(v, "name"),
+			FullAddress: mapString(v, "full_address"),
+		}, nil
+	case []any:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		return ParseLocation(v[0])
+	default:
+		return nil, fmt.Errorf("ParseLocation: invalid type %T", val)
+	}
+}
+
+// LocationFieldParser sets a Location field, or the full address into a string field.
+func LocationFieldParser(dest reflect.Value, val any) error {
+	location, err := ParseLocation(val)
+	if err != nil || location == nil {
+		return err
+	}
+
+	if v := maparser.AllocIndirect(dest); v.Kind() == reflect.String {
+		v.SetString(location.FullAddress)
+		return nil
+	}
+
+	maparser.SetValue(dest, *location)
+	return nil
+}
+
+// LocationFieldEncoder encodes a Location as the "longitude,latitude" string a location field accepts.
+func LocationFieldEncoder(src reflect.Value) (any, error) {
+	val := maparser.GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	// a full address string can not be written back, the field is read only.
+	if _, ok := val.(string); ok {
+		return nil, nil
+	}
+
+	location, ok := val.(Location)
+	if !ok {
+		return nil, fmt.Errorf("LocationFieldEncoder: invalid type %T", val)
+	}
+
+	if location.Location == "" {
+		return nil, nil
+	}
+
+	return location.Location, nil
+}
+
+// ProgressFieldParser sets the progress fraction, e.g. 0.35 or "35%", into a float field.
+func ProgressFieldParser(dest reflect.Value, val any) error {
+	return maparser.SetNumber(dest, val, maparser.RoundError)
+}
+
+// RatingArgsFieldParser sets a rating into an int field, rounding by the `round` argument.
+func RatingArgsFieldParser(dest reflect.Value, val any, args maparser.Args) error {
+	return maparser.SetNumber(dest, val, maparser.ArgsRoundMode(args))
+}
+
+// ParseAutoNumber returns the text of an auto number field, e.g. "NO-0001".
+func ParseAutoNumber(val any) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []any:
+		if len(v) == 0 {
+			return "", nil
+		}
+		return ParseAutoNumber(v[0])
+	default:
+		return "", fmt.Errorf("ParseAutoNumber: invalid type %T", val)
+	}
+}
+
+// AutoNumberFieldParser sets the auto number text into a string field,
+// or its trailing sequence number, e.g. 1 of "NO-0001", into an int field.
+func AutoNumberFieldParser(dest reflect.Value, val any) error {
+	s, err := ParseAutoNumber(val)
+	if err != nil || s == "" {
+		return err
+	}
+
+	v := maparser.AllocIndirect(dest)
+	if v.Kind() == reflect.String {
+		v.SetString(s)
+		return nil
+	}
+
+	digits := s[len(strings.TrimRight(s, "0123456789")):]
+	if digits == "" {
+		return fmt.Errorf("AutoNumberFieldParser: no sequence number in %s", s)
+	}
+
+	return maparser.SetNumber(v, digits, maparser.RoundError)
+}
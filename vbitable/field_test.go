@@ -0,0 +1,107 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+// loadFixture loads the record fields of a fixture in testdata, which follow the shapes of
+// the bitable record api responses.
+func loadFixture(t *testing.T, name string) map[string]any {
+	data, err := os.ReadFile("testdata/" + name)
+	assert.Nil(t, err)
+
+	var m map[string]any
+	assert.Nil(t, json.Unmarshal(data, &m))
+
+	return m
+}
+
+type FieldRecord struct {
+	Phone       string    `json:"phone" key:"电话" parser:"phone"`
+	Email       string    `json:"email" key:"邮箱" parser:"email"`
+	Site        Link      `json:"site" key:"官网"`
+	SiteURL     string    `json:"site_url" key:"官网,readonly" parser:"url"`
+	Address     *Location `json:"address" key:"地址"`
+	FullAddress string    `json:"full_address" key:"地址,readonly" parser:"location"`
+	Rating      int       `json:"rating" key:"评分" parser:"rating"`
+	Progress    float64   `json:"progress" key:"进度" parser:"progress"`
+	FormulaLink *Link     `json:"formula_link" key:"公式链接" parser:"url"`
+}
+
+func TestParseFieldFixtures(t *testing.T) {
+	record := &FieldRecord{}
+	assert.Nil(t, maparser.Parse(record, loadFixture(t, "record_fields.json")))
+
+	assert.Equal(t, "13800138000", record.Phone)
+	assert.Equal(t, "vogo@example.com", record.Email)
+	assert.Equal(t, Link{Text: "开发文档", Link: "https://www.example.com/docs?id=1"}, record.Site)
+	assert.Equal(t, "https://www.example.com/docs?id=1", record.SiteURL)
+	assert.Equal(t, &Location{
+		Location:    "116.397026,39.918058",
+		Province:    "北京市",
+		City:        "北京市",
+		District:    "东城区",
+		Address:     "景山前街4号",
+		Name:        "故宫博物院",
+		FullAddress: "北京市东城区景山前街4号故宫博物院",
+	}, record.Address)
+	assert.Equal(t, "北京市东城区景山前街4号故宫博物院", record.FullAddress)
+	assert.Equal(t, 4, record.Rating)
+	assert.Equal(t, 0.35, record.Progress)
+	assert.Equal(t, &Link{Text: "https://www.example.com", Link: "https://www.example.com"}, record.FormulaLink)
+
+	lng, lat, err := record.Address.LngLat()
+	assert.Nil(t, err)
+	assert.Equal(t, 116.397026, lng)
+	assert.Equal(t, 39.918058, lat)
+
+	m, err := maparser.Encode(&FieldRecord{
+		Phone:    record.Phone,
+		Email:    record.Email,
+		Site:     record.Site,
+		Address:  record.Address,
+		Rating:   record.Rating,
+		Progress: record.Progress,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"电话": "13800138000",
+		"邮箱": "vogo@example.com",
+		"官网": map[string]any{"text": "开发文档", "link": "https://www.example.com/docs?id=1"},
+		"地址": "116.397026,39.918058",
+		"评分": int64(4),
+		"进度": 0.35,
+	}, m)
+}
+
+func TestParseFieldErrors(t *testing.T) {
+	assert.NotNil(t, maparser.Parse(&FieldRecord{}, map[string]any{"官网": float64(1)}))
+	assert.NotNil(t, maparser.Parse(&FieldRecord{}, map[string]any{"地址": "北京"}))
+	assert.NotNil(t, maparser.Parse(&FieldRecord{}, map[string]any{"评分": "很好"}))
+	assert.Nil(t, maparser.Parse(&FieldRecord{}, map[string]any{"进度": "35%"}))
+
+	_, _, err := (&Location{Location: "116.39"}).LngLat()
+	assert.NotNil(t, err)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/vogo/vlarksdk/maparser"
 	"github.com/vogo/vlarksdk/vutil"
 )
 
@@ -100,7 +101,35 @@ func FileArrayFieldParser(dest reflect.Value, val any) error {
 		return nil
 	}
 
-	dest.Set(reflect.ValueOf(fileInfo))
+	maparser.SetValue(dest, fileInfo)
 
 	return nil
 }
+
+// FileArrayFieldEncoder encodes attachments as file token objects, which is what the
+// bitable api accepts when writing an attachment field.
+func FileArrayFieldEncoder(src reflect.Value) (any, error) {
+	val := maparser.GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	infoArr, ok := val.([]*FileInfo)
+	if !ok {
+		return nil, fmt.Errorf("FileArrayFieldEncoder: invalid type %T", val)
+	}
+
+	if len(infoArr) == 0 {
+		return nil, nil
+	}
+
+	tokens := make([]map[string]any, 0, len(infoArr))
+	for _, info := range infoArr {
+		if info == nil {
+			continue
+		}
+		tokens = append(tokens, map[string]any{"file_token": info.FileToken})
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,203 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+// FieldType is the type code of a bitable field, declared by the `type` of formula and lookup values.
+type FieldType int
+
+const (
+	FieldTypeText         FieldType = 1
+	FieldTypeNumber       FieldType = 2
+	FieldTypeSingleSelect FieldType = 3
+	FieldTypeMultiSelect  FieldType = 4
+	FieldTypeDateTime     FieldType = 5
+	FieldTypeCheckbox     FieldType = 7
+	FieldTypeUser         FieldType = 11
+	FieldTypePhone        FieldType = 13
+	FieldTypeURL          FieldType = 15
+	FieldTypeAttachment   FieldType = 17
+	FieldTypeSingleLink   FieldType = 18
+	FieldTypeLookup       FieldType = 19
+	FieldTypeFormula      FieldType = 20
+	FieldTypeDuplexLink   FieldType = 21
+	FieldTypeLocation     FieldType = 22
+	FieldTypeGroupChat    FieldType = 23
+	FieldTypeCreatedTime  FieldType = 1001
+	FieldTypeModifiedTime FieldType = 1002
+	FieldTypeCreatedUser  FieldType = 1003
+	FieldTypeModifiedUser FieldType = 1004
+	FieldTypeAutoNumber   FieldType = 1005
+)
+
+// fieldTypeParsers are the parsers of the values of each field type.
+var fieldTypeParsers = map[FieldType]string{
+	FieldTypeText:         "map_field_text",
+	FieldTypeNumber:       "float",
+	FieldTypeSingleSelect: "single_select",
+	FieldTypeMultiSelect:  "multi_select",
+	FieldTypeDateTime:     "timestamp",
+	FieldTypeCheckbox:     "bool",
+	FieldTypeUser:         "multiple_users",
+	FieldTypePhone:        "phone",
+	FieldTypeURL:          "url",
+	FieldTypeAttachment:   "file_array",
+	FieldTypeSingleLink:   "link_records",
+	FieldTypeDuplexLink:   "link_records",
+	FieldTypeLocation:     "location",
+	FieldTypeGroupChat:    "group_chat",
+	FieldTypeCreatedTime:  "timestamp",
+	FieldTypeModifiedTime: "timestamp",
+	FieldTypeCreatedUser:  "multiple_users",
+	FieldTypeModifiedUser: "multiple_users",
+	FieldTypeAutoNumber:   "auto_number",
+}
+
+// UnwrapFormula returns the inner value and the declared field type of a formula or lookup value
+// `{"type": 2, "value": [12.5]}`, unwrapping nested envelopes. Other values are returned as is
+// with a zero type.
+func UnwrapFormula(val any) (any, FieldType) {
+	var fieldType FieldType
+	for {
+		m, ok := val.(map[string]any)
+		if !ok {
+			return val, fieldType
+		}
+
+		typeVal, typeOk := m["type"]
+		inner, valueOk := m["value"]
+		if !typeOk || !valueOk {
+			return val, fieldType
+		}
+
+		code, err := maparser.ToInt64(typeVal, maparser.RoundError)
+		if err != nil {
+			return val, fieldType
+		}
+
+		val, fieldType = inner, FieldType(code)
+	}
+}
+
+// NewFormulaFieldParser returns the parser of formula and lookup fields, which unwraps the value
+// and delegates to the parser of the `as` argument, or to the parser of the declared field type,
+// or to the parser inferred from the field type if the former can not set the field.
+// The other arguments are passed to the delegated parser, e.g. `formula(as=timestamp,tz=Asia/Shanghai)`.
+func NewFormulaFieldParser(p *maparser.Parser) maparser.ArgsFieldParser {
+	return func(dest reflect.Value, val any, args maparser.Args) error {
+		inner, fieldType := UnwrapFormula(val)
+		if inner == nil {
+			return nil
+		}
+
+		parser, err := formulaFieldParser(p, dest.Type(), fieldType, args)
+		if err != nil {
+			return err
+		}
+
+		return parser(dest, inner, delegateArgs(args))
+	}
+}
+
+func formulaFieldParser(p *maparser.Parser, t reflect.Type, fieldType FieldType, args maparser.Args,
+) (maparser.ArgsFieldParser, error) {
+	if name, ok := args["as"]; ok {
+		parser, parserOk := p.FieldParser(name, t)
+		if !parserOk {
+			return nil, fmt.Errorf("FormulaFieldParser: parser %s can not set type %s", name, t)
+		}
+		return parser, nil
+	}
+
+	if name, ok := fieldTypeParsers[fieldType]; ok {
+		if parser, parserOk := p.FieldParser(name, t); parserOk {
+			return parser, nil
+		}
+	}
+
+	if name, ok := p.InferParser(t); ok {
+		if parser, parserOk := p.FieldParser(name, t); parserOk {
+			return parser, nil
+		}
+	}
+
+	return nil, fmt.Errorf("FormulaFieldParser: no parser of field type %d for type %s", fieldType, t)
+}
+
+// delegateArgs removes the `as` argument.
+func delegateArgs(args maparser.Args) maparser.Args {
+	if _, ok := args["as"]; !ok {
+		return args
+	}
+
+	delegated := make(maparser.Args, len(args)-1)
+	for k, v := range args {
+		if k != "as" {
+			delegated[k] = v
+		}
+	}
+
+	return delegated
+}
+
+// FormulaParsers binds the value and field parser of formula and lookup fields to p,
+// registered with maparser.Parser.SetBoundFieldParser so that a copied parser delegates to its own parsers.
+func FormulaParsers(p *maparser.Parser) (maparser.ValueParser, maparser.ArgsFieldParser) {
+	return NewFormulaValueParser(p), NewFormulaFieldParser(p)
+}
+
+// NewFormulaArgsValidator returns the args validator of formula and lookup fields, which checks that
+// the `as` argument names a parser of p and validates the other arguments by its validator.
+// Without `as` the delegated parser is only known when parsing, the `tz` and `round` arguments are checked.
+func NewFormulaArgsValidator(p *maparser.Parser) maparser.ArgsValidator {
+	return func(args maparser.Args) error {
+		if name, ok := args["as"]; ok {
+			return p.ValidateArgs(name, delegateArgs(args))
+		}
+
+		if err := validateLocationArgs(args); err != nil {
+			return err
+		}
+
+		return maparser.ValidateRoundArgs(args)
+	}
+}
+
+// NewFormulaValueParser returns the value parser of formula and lookup fields, which unwraps the value
+// and converts it by the value parser of the declared field type. Values of other types are returned unwrapped.
+func NewFormulaValueParser(p *maparser.Parser) maparser.ValueParser {
+	return func(val any) (any, error) {
+		inner, fieldType := UnwrapFormula(val)
+		if inner == nil {
+			return nil, nil
+		}
+
+		name, ok := fieldTypeParsers[fieldType]
+		if !ok {
+			return inner, nil
+		}
+
+		return p.ParseValue(name, inner)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+type FormulaRecord struct {
+	Text    string           `json:"text" key:"公式文本" parser:"formula"`
+	Number  float64          `json:"number" key:"公式数字" parser:"formula"`
+	Int     int              `json:"int" key:"公式整数" parser:"formula"`
+	Date    time.Time        `json:"date" key:"公式日期" parser:"formula(tz=Asia/Shanghai)"`
+	Owner   *LarkUser        `json:"owner" key:"引用负责人" parser:"lookup"`
+	Owners  []*LarkUser      `json:"owners" key:"引用负责人" parser:"lookup"`
+	Status  TaskStatus       `json:"status" key:"引用状态" parser:"lookup"`
+	Tags    []TaskTag        `json:"tags" key:"引用标签" parser:"lookup"`
+	Checked bool             `json:"checked" key:"公式勾选" parser:"formula"`
+	Nested  int64            `json:"nested" key:"引用公式" parser:"lookup"`
+	Amount  maparser.Decimal `json:"amount" key:"公式金额" parser:"formula"`
+}
+
+func TestParseFormula(t *testing.T) {
+	record := &FormulaRecord{}
+	assert.Nil(t, maparser.Parse(record, loadFixture(t, "formula_fields.json")))
+
+	assert.Equal(t, "官网改版-进行中", record.Text)
+	assert.Equal(t, 12.5, record.Number)
+	assert.Equal(t, 42, record.Int)
+	assert.Equal(t, "ou_1", record.Owner.OpenId)
+	assert.Len(t, record.Owners, 2)
+	assert.Equal(t, TaskStatusDoing, record.Status)
+	assert.Equal(t, []TaskTag{"urgent", "important"}, record.Tags)
+	assert.True(t, record.Checked)
+	assert.Equal(t, int64(3), record.Nested)
+	assert.Equal(t, maparser.NewDecimal(12345, 1), record.Amount)
+
+	assert.Equal(t, int64(1700000000000), record.Date.UnixMilli())
+	assert.Equal(t, "Asia/Shanghai", record.Date.Location().String())
+}
+
+func TestParseFormulaErrors(t *testing.T) {
+	type IntRecord struct {
+		Int int `json:"int" key:"公式" parser:"formula(round=error)"`
+	}
+	err := maparser.Parse(&IntRecord{}, map[string]any{"公式": map[string]any{"type": float64(2), "value": []any{1.5}}})
+	assert.NotNil(t, err)
+
+	type MapRecord struct {
+		Values map[string]any `json:"values" key:"公式" parser:"formula"`
+	}
+	err = maparser.Parse(&MapRecord{}, map[string]any{"公式": map[string]any{"type": float64(1), "value": []any{"a"}}})
+	assert.ErrorContains(t, err, "no parser of field type 1")
+
+	type AsRecord struct {
+		Int  int    `json:"int" key:"公式" parser:"formula(as=timestamp)"`
+		Text string `json:"text" key:"文本" parser:"formula(as=map_field_text,sep=;)"`
+	}
+	err = maparser.Parse(&AsRecord{}, map[string]any{"公式": map[string]any{"type": float64(2), "value": []any{1}}})
+	assert.ErrorContains(t, err, "parser timestamp can not set type int")
+
+	record := &AsRecord{}
+	err = maparser.Parse(record, map[string]any{"文本": map[string]any{"type": float64(1), "value": []any{"a", "b"}}})
+	assert.Nil(t, err)
+	assert.Equal(t, "a;b", record.Text)
+
+	// the arguments are checked by Validate, by the validator of the parser of `as`.
+	type UnknownAsRecord struct {
+		Number float64 `json:"number" key:"公式" parser:"formula(as=nope,tz=Bad/Zone)"`
+	}
+	assert.ErrorContains(t, maparser.Validate[UnknownAsRecord](), "UnknownAsRecord.Number: parser formula: invalid parser nope")
+
+	type BadTzRecord struct {
+		Date time.Time `json:"date" key:"公式" parser:"lookup(as=timestamp,tz=Bad/Zone)"`
+	}
+	assert.ErrorContains(t, maparser.Validate[BadTzRecord](), "parser lookup: parser timestamp: invalid time zone Bad/Zone")
+
+	type BadRoundRecord struct {
+		Int int `json:"int" key:"公式" parser:"formula(round=up)"`
+	}
+	assert.ErrorContains(t, maparser.Validate[BadRoundRecord](), "parser formula: invalid round mode up")
+}
+
+func TestParseFormulaValue(t *testing.T) {
+	v, err := maparser.ParseValue("formula", map[string]any{"type": float64(2), "value": []any{12.5}})
+	assert.Nil(t, err)
+	assert.Equal(t, 12.5, v)
+
+	v, err = maparser.ParseValue("lookup", map[string]any{"type": float64(5), "value": []any{float64(1700000000000)}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1700000000000), v.(time.Time).UnixMilli())
+
+	v, err = maparser.ParseValue("formula", "plain")
+	assert.Nil(t, err)
+	assert.Equal(t, "plain", v)
+}
+
+func TestParseFormulaCopiedParser(t *testing.T) {
+	type NumberRecord struct {
+		Number float64 `json:"number" key:"公式" parser:"formula"`
+	}
+
+	p := maparser.New(maparser.WithParsersFrom(maparser.Default()))
+	p.SetFieldParser("float", nil, func(dest reflect.Value, val any) error {
+		dest.SetFloat(-1)
+		return nil
+	})
+
+	formula := map[string]any{"公式": map[string]any{"type": float64(2), "value": []any{12.5}}}
+
+	record := &NumberRecord{}
+	assert.Nil(t, p.Parse(record, formula))
+	assert.Equal(t, float64(-1), record.Number)
+
+	record = &NumberRecord{}
+	assert.Nil(t, maparser.Parse(record, formula))
+	assert.Equal(t, 12.5, record.Number)
+
+	// the validator checks `as` against the parsers of the copy.
+	type LabelRecord struct {
+		Number float64 `json:"number" key:"公式" parser:"formula(as=label)"`
+	}
+	assert.NotNil(t, maparser.Validate[LabelRecord]())
+	p.SetFieldParser("label", nil, maparser.FloatFieldParser)
+	assert.Nil(t, maparser.ValidateWith[LabelRecord](p))
+}
@@ -0,0 +1,147 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+// LarkGroup is a group chat of a group chat field.
+type LarkGroup struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// ParseGroup parses a group chat object, the first one of an array.
+func ParseGroup(val any) (*LarkGroup, error) {
+	groups, err := ParseGroups(val)
+	if err != nil || len(groups) == 0 {
+		return nil, err
+	}
+
+	return groups[0], nil
+}
+
+// ParseGroups parses the group chat objects of a group chat field.
+func ParseGroups(val any) ([]*LarkGroup, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		g, err := parseGroupMap(v)
+		if err != nil {
+			return nil, err
+		}
+		return []*LarkGroup{g}, nil
+	case []any:
+		groups := make([]*LarkGroup, 0, len(v))
+		for _, item := range v {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("ParseGroups: invalid type %T", item)
+			}
+
+			g, err := parseGroupMap(m)
+			if err != nil {
+				return nil, err
+			}
+			groups = append(groups, g)
+		}
+		return groups, nil
+	default:
+		return nil, fmt.Errorf("ParseGroups: invalid type %T", val)
+	}
+}
+
+func parseGroupMap(m map[string]any) (*LarkGroup, error) {
+	id, ok := m["id"].(string)
+	if !ok {
+		return nil, fmt.Errorf("ParseGroups: %s", "id field not found")
+	}
+
+	return &LarkGroup{
+		ID:        id,
+		Name:      mapString(m, "name"),
+		AvatarURL: mapString(m, "avatar_url"),
+	}, nil
+}
+
+// GroupChatFieldParser sets the group chats into a []*LarkGroup field,
+// the first one into a *LarkGroup field, or the names joined with "," into a string field.
+func GroupChatFieldParser(dest reflect.Value, val any) error {
+	groups, err := ParseGroups(val)
+	if err != nil || groups == nil {
+		return err
+	}
+
+	switch {
+	case dest.Type() == reflect.TypeOf(&LarkGroup{}):
+		if len(groups) > 0 {
+			dest.Set(reflect.ValueOf(groups[0]))
+		}
+	case maparser.StringTarget.Accepts(dest.Type()):
+		names := make([]string, 0, len(groups))
+		for _, g := range groups {
+			names = append(names, g.Name)
+		}
+		maparser.AllocIndirect(dest).SetString(strings.Join(names, ","))
+	default:
+		maparser.SetValue(dest, groups)
+	}
+
+	return nil
+}
+
+// GroupChatFieldEncoder encodes group chats as the chat id array a group chat field accepts.
+func GroupChatFieldEncoder(src reflect.Value) (any, error) {
+	val := maparser.GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	var groups []*LarkGroup
+	switch v := val.(type) {
+	case []*LarkGroup:
+		groups = v
+	case LarkGroup:
+		groups = []*LarkGroup{&v}
+	case string:
+		// a string field holds the names, which can not be written back.
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("GroupChatFieldEncoder: invalid type %T", val)
+	}
+
+	chats := make([]map[string]any, 0, len(groups))
+	for _, g := range groups {
+		if g != nil && g.ID != "" {
+			chats = append(chats, map[string]any{"id": g.ID})
+		}
+	}
+
+	if len(chats) == 0 {
+		return nil, nil
+	}
+
+	return chats, nil
+}
@@ -17,22 +17,141 @@
 
 package vbitable
 
-import "github.com/vogo/vlarksdk/maparser"
+import (
+	"reflect"
+	"time"
+
+	"github.com/vogo/vlarksdk/maparser"
+)
 
 func init() {
-	maparser.SetFieldParser("single_user_name_email", nil, SingleUserNameEmail)
-	maparser.SetFieldParser("single_user_email", nil, SingleUserEmail)
-	maparser.SetFieldParser("single_user_name", nil, SingleUserName)
-	maparser.SetFieldParser("single_user_id", nil, SingleUserId)
-	maparser.SetFieldParser("multiple_user_name_email", nil, MultipleUserNameEmail)
-	maparser.SetFieldParser("single_user", nil, SingleUser)
-	maparser.SetFieldParser("multiple_users", nil, MultipleUsers)
-	maparser.SetFieldParser("map_field_text", MapFieldTextValueParser, MapFieldTextFieldParser)
-	maparser.SetFieldParser("map_field_text_link", nil, MapFieldTextLinkParser)
-	maparser.SetFieldParser("map_field_text_date", nil, MapFieldTextDateParser)
-	maparser.SetFieldParser("timestamp", TimestampValueParser, TimestampFieldParser)
-	maparser.SetFieldParser("lark_days", nil, LarkDaysParser)
-	maparser.SetFieldParser("func_int", nil, FuncIntParser)
-	maparser.SetFieldParser("map_field_attach", nil, MapFieldAttachParser)
-	maparser.SetFieldParser("file_array", FileArrayValueParser, FileArrayFieldParser)
+	RegisterParsers(maparser.Default())
+}
+
+// RegisterParsers registers the bitable field parsers and encoders into p,
+// importing this package registers them into the default parser.
+func RegisterParsers(p *maparser.Parser) {
+	p.SetFieldParser("single_user_name_email", maparser.ValueParserOf(ParseUserNameEmail), SingleUserNameEmail)
+	p.SetFieldParser("single_user_email", maparser.ValueParserOf(ParseUserEmail), SingleUserEmail)
+	p.SetFieldParser("single_user_name", maparser.ValueParserOf(ParseUserName), SingleUserName)
+	p.SetFieldParser("single_user_id", maparser.ValueParserOf(ParseUserId), SingleUserId)
+	p.SetFieldParser("multiple_user_name_email", nil, MultipleUserNameEmail)
+	p.SetArgsFieldParser("single_user", maparser.ValueParserOf(ParseUser), SingleUserArgs)
+	p.SetArgsFieldParser("multiple_users", maparser.ValueParserOf(ParseMultipleUsers), MultipleUsersArgs)
+	p.SetArgsFieldParser("map_field_text", MapFieldTextValueParser, MapFieldTextArgsFieldParser)
+	p.SetFieldParser("map_field_text_link", maparser.ValueParserOf(ParseMapFieldTextLink), MapFieldTextLinkParser)
+	p.SetArgsFieldParser("map_field_text_date", nil, MapFieldTextDateArgsParser)
+	p.SetArgsFieldParser("timestamp", TimestampValueParser, TimestampArgsFieldParser)
+	p.SetFieldParser("lark_days", nil, LarkDaysParser)
+	p.SetFieldParser("func_int", nil, FuncIntParser)
+	p.SetFieldParser("map_field_attach", maparser.ValueParserOf(ParseMapFieldAttachUrls), MapFieldAttachParser)
+	p.SetFieldParser("file_array", FileArrayValueParser, FileArrayFieldParser)
+	p.SetFieldParser("rich_text", maparser.ValueParserOf(ParseRichText), RichTextFieldParser)
+	p.SetArgsFieldParser("phone", MapFieldTextValueParser, MapFieldTextArgsFieldParser)
+	p.SetArgsFieldParser("email", MapFieldTextValueParser, MapFieldTextArgsFieldParser)
+	p.SetFieldParser("url", maparser.ValueParserOf(ParseLink), LinkFieldParser)
+	p.SetFieldParser("location", maparser.ValueParserOf(ParseLocation), LocationFieldParser)
+	p.SetArgsFieldParser("rating", maparser.IntValueParser, RatingArgsFieldParser)
+	p.SetFieldParser("progress", maparser.FloatValueParser, ProgressFieldParser)
+	p.SetFieldParser("link_record_ids", maparser.ValueParserOf(ParseRecordIds), LinkRecordIdsFieldParser)
+	p.SetFieldParser("link_records", maparser.ValueParserOf(ParseRecordRefs), LinkRecordsFieldParser)
+	p.SetFieldParser("group_chat", maparser.ValueParserOf(ParseGroups), GroupChatFieldParser)
+	p.SetArgsFieldParser("created_user", maparser.ValueParserOf(ParseUser), SingleUserArgs)
+	p.SetArgsFieldParser("modified_user", maparser.ValueParserOf(ParseUser), SingleUserArgs)
+	p.SetArgsFieldParser("created_time", TimestampValueParser, TimestampArgsFieldParser)
+	p.SetArgsFieldParser("modified_time", TimestampValueParser, TimestampArgsFieldParser)
+	p.SetFieldParser("auto_number", maparser.ValueParserOf(ParseAutoNumber), AutoNumberFieldParser)
+	p.SetBoundFieldParser("formula", FormulaParsers)
+	p.SetBoundFieldParser("lookup", FormulaParsers)
+	p.SetBoundFieldParser("single_select", SingleSelectParsers)
+	p.SetBoundFieldParser("multi_select", MultiSelectParsers)
+
+	p.SetFieldEncoder("single_user_id", SingleUserIdEncoder)
+	p.SetFieldEncoder("single_user", SingleUserEncoder)
+	p.SetFieldEncoder("multiple_users", MultipleUsersEncoder)
+	p.SetFieldEncoder("map_field_text", maparser.StringFieldEncoder)
+	p.SetFieldEncoder("map_field_text_date", TimestampFieldEncoder)
+	p.SetFieldEncoder("timestamp", TimestampFieldEncoder)
+	p.SetFieldEncoder("file_array", FileArrayFieldEncoder)
+	p.SetFieldEncoder("rich_text", RichTextFieldEncoder)
+	p.SetFieldEncoder("phone", maparser.StringFieldEncoder)
+	p.SetFieldEncoder("email", maparser.StringFieldEncoder)
+	p.SetFieldEncoder("url", LinkFieldEncoder)
+	p.SetFieldEncoder("location", LocationFieldEncoder)
+	p.SetFieldEncoder("rating", maparser.IntFieldEncoder)
+	p.SetFieldEncoder("progress", maparser.FloatFieldEncoder)
+	p.SetFieldEncoder("link_record_ids", LinkRecordIdsFieldEncoder)
+	p.SetFieldEncoder("link_records", LinkRecordsFieldEncoder)
+	p.SetFieldEncoder("group_chat", GroupChatFieldEncoder)
+	p.SetBoundFieldEncoder("single_select", NewSingleSelectFieldEncoder)
+	p.SetBoundFieldEncoder("multi_select", NewMultiSelectFieldEncoder)
+
+	timeTarget := maparser.TypesTarget(reflect.TypeOf(time.Time{}))
+	for _, name := range []string{
+		"single_user_name_email", "single_user_email", "single_user_name", "single_user_id",
+		"multiple_user_name_email", "map_field_text", "map_field_text_link", "map_field_attach",
+		"phone", "email",
+	} {
+		p.SetFieldTarget(name, maparser.StringTarget)
+	}
+	p.SetFieldTarget("single_user", maparser.TypesTarget(reflect.TypeOf(&LarkUser{})))
+	p.SetFieldTarget("multiple_users", maparser.TypesTarget(reflect.TypeOf([]*LarkUser{})))
+	p.SetFieldTarget("map_field_text_date", timeTarget)
+	p.SetFieldTarget("timestamp", timeTarget)
+	p.SetFieldTarget("lark_days", timeTarget)
+	p.SetFieldTarget("func_int", maparser.IntTarget)
+	p.SetFieldTarget("file_array", maparser.TypesTarget(reflect.TypeOf([]*FileInfo{})))
+	p.SetFieldTarget("rich_text", maparser.TypesTarget(reflect.TypeOf(RichText{})))
+	p.SetFieldTarget("url", maparser.FieldTarget{
+		Kinds: []reflect.Kind{reflect.String},
+		Types: []reflect.Type{reflect.TypeOf(Link{})},
+	})
+	p.SetFieldTarget("location", maparser.FieldTarget{
+		Kinds: []reflect.Kind{reflect.String},
+		Types: []reflect.Type{reflect.TypeOf(Location{})},
+	})
+	p.SetFieldTarget("rating", maparser.IntTarget)
+	p.SetFieldTarget("progress", maparser.FloatTarget)
+	p.SetFieldTarget("link_record_ids", maparser.TypesTarget(reflect.TypeOf([]string{})))
+	p.SetFieldTarget("link_records", maparser.TypesTarget(reflect.TypeOf([]*RecordRef{}), reflect.TypeOf(&RecordRef{})))
+	p.SetFieldTarget("group_chat", maparser.FieldTarget{
+		Kinds: []reflect.Kind{reflect.String},
+		Types: []reflect.Type{reflect.TypeOf([]*LarkGroup{}), reflect.TypeOf(&LarkGroup{})},
+	})
+	p.SetFieldTarget("created_user", maparser.TypesTarget(reflect.TypeOf(&LarkUser{})))
+	p.SetFieldTarget("modified_user", maparser.TypesTarget(reflect.TypeOf(&LarkUser{})))
+	p.SetFieldTarget("created_time", timeTarget)
+	p.SetFieldTarget("modified_time", timeTarget)
+	p.SetFieldTarget("auto_number", maparser.FieldTarget{
+		Kinds: append([]reflect.Kind{reflect.String}, maparser.IntTarget.Kinds...),
+	})
+	p.SetFieldTarget("single_select", maparser.FieldTarget{
+		Kinds: append([]reflect.Kind{reflect.String}, maparser.IntTarget.Kinds...),
+	})
+	p.SetFieldTarget("multi_select", maparser.FieldTarget{Kinds: []reflect.Kind{reflect.String, reflect.Slice}})
+
+	for _, name := range []string{"timestamp", "map_field_text_date", "created_time", "modified_time"} {
+		p.SetArgsValidator(name, validateLocationArgs)
+	}
+	for _, name := range []string{"single_user", "multiple_users", "created_user", "modified_user"} {
+		p.SetArgsValidator(name, validateUserArgs)
+	}
+	p.SetArgsValidator("rating", maparser.ValidateRoundArgs)
+	p.SetBoundArgsValidator("formula", NewFormulaArgsValidator)
+	p.SetBoundArgsValidator("lookup", NewFormulaArgsValidator)
+
+	p.SetTypeParser(reflect.TypeOf(time.Time{}), "timestamp")
+	p.SetTypeParser(reflect.TypeOf(&time.Time{}), "timestamp")
+	p.SetTypeParser(reflect.TypeOf([]*FileInfo{}), "file_array")
+	p.SetTypeParser(reflect.TypeOf(&LarkUser{}), "single_user")
+	p.SetTypeParser(reflect.TypeOf([]*LarkUser{}), "multiple_users")
+	p.SetTypeParser(reflect.TypeOf(RichText{}), "rich_text")
+	p.SetTypeParser(reflect.TypeOf(Link{}), "url")
+	p.SetTypeParser(reflect.TypeOf(&Link{}), "url")
+	p.SetTypeParser(reflect.TypeOf([]*RecordRef{}), "link_records")
+	p.SetTypeParser(reflect.TypeOf(&RecordRef{}), "link_records")
+	p.SetTypeParser(reflect.TypeOf([]*LarkGroup{}), "group_chat")
+	p.SetTypeParser(reflect.TypeOf(&LarkGroup{}), "group_chat")
+	p.SetTypeParser(reflect.TypeOf(Location{}), "location")
+	p.SetTypeParser(reflect.TypeOf(&Location{}), "location")
 }
@@ -0,0 +1,195 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+// RecordRef is a record linked by a single or duplex link field.
+type RecordRef struct {
+	RecordID string `json:"record_id"`
+	TableID  string `json:"table_id,omitempty"`
+
+	// Text is the display text of the record, the value of the primary field of the linked table.
+	Text string `json:"text,omitempty"`
+}
+
+// ParseRecordRefs parses a link field, which is either `{"link_record_ids": [...]}`,
+// an array of `{"record_ids": [...], "table_id": ..., "text_arr": [...]}` objects,
+// or an array of record ids.
+func ParseRecordRefs(val any) ([]*RecordRef, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		return []*RecordRef{{RecordID: v}}, nil
+	case map[string]any:
+		if ids, ok := v["link_record_ids"]; ok {
+			return ParseRecordRefs(ids)
+		}
+		return parseLinkObject(v)
+	case []string:
+		refs := make([]*RecordRef, 0, len(v))
+		for _, id := range v {
+			refs = append(refs, &RecordRef{RecordID: id})
+		}
+		return refs, nil
+	case []any:
+		refs := make([]*RecordRef, 0, len(v))
+		for _, item := range v {
+			itemRefs, err := ParseRecordRefs(item)
+			if err != nil {
+				return nil, err
+			}
+			refs = append(refs, itemRefs...)
+		}
+		return refs, nil
+	default:
+		return nil, fmt.Errorf("ParseRecordRefs: invalid type %T", val)
+	}
+}
+
+// parseLinkObject parses a link object, pairing each record id with the text at the same index.
+func parseLinkObject(m map[string]any) ([]*RecordRef, error) {
+	ids, ok := m["record_ids"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("ParseRecordRefs: record_ids not found")
+	}
+
+	texts, _ := m["text_arr"].([]any)
+	tableID := mapString(m, "table_id")
+
+	refs := make([]*RecordRef, 0, len(ids))
+	for i, id := range ids {
+		s, isStr := id.(string)
+		if !isStr {
+			return nil, fmt.Errorf("ParseRecordRefs: invalid record id type %T", id)
+		}
+
+		ref := &RecordRef{RecordID: s, TableID: tableID}
+		if i < len(texts) {
+			ref.Text, _ = texts[i].(string)
+		} else if len(ids) == 1 {
+			ref.Text = mapString(m, "text")
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
+
+// ParseRecordIds returns the linked record ids of a link field.
+func ParseRecordIds(val any) ([]string, error) {
+	refs, err := ParseRecordRefs(val)
+	if err != nil || refs == nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.RecordID)
+	}
+
+	return ids, nil
+}
+
+func LinkRecordIdsFieldParser(dest reflect.Value, val any) error {
+	ids, err := ParseRecordIds(val)
+	if err != nil || ids == nil {
+		return err
+	}
+
+	maparser.SetValue(dest, ids)
+	return nil
+}
+
+// LinkRecordsFieldParser sets the linked records into a []*RecordRef field,
+// or the first one into a *RecordRef field of a single link.
+func LinkRecordsFieldParser(dest reflect.Value, val any) error {
+	refs, err := ParseRecordRefs(val)
+	if err != nil || refs == nil {
+		return err
+	}
+
+	if dest.Type() == reflect.TypeOf(&RecordRef{}) {
+		if len(refs) > 0 {
+			dest.Set(reflect.ValueOf(refs[0]))
+		}
+		return nil
+	}
+
+	maparser.SetValue(dest, refs)
+	return nil
+}
+
+// LinkRecordIdsFieldEncoder encodes the record ids as the id array a link field accepts.
+func LinkRecordIdsFieldEncoder(src reflect.Value) (any, error) {
+	val := maparser.GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	ids, ok := val.([]string)
+	if !ok {
+		return nil, fmt.Errorf("LinkRecordIdsFieldEncoder: invalid type %T", val)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return ids, nil
+}
+
+// LinkRecordsFieldEncoder encodes the linked records as their id array.
+func LinkRecordsFieldEncoder(src reflect.Value) (any, error) {
+	val := maparser.GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	var refs []*RecordRef
+	switch v := val.(type) {
+	case []*RecordRef:
+		refs = v
+	case RecordRef:
+		refs = []*RecordRef{&v}
+	default:
+		return nil, fmt.Errorf("LinkRecordsFieldEncoder: invalid type %T", val)
+	}
+
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref != nil && ref.RecordID != "" {
+			ids = append(ids, ref.RecordID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return ids, nil
+}
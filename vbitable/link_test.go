@@ -0,0 +1,62 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+type LinkRecord struct {
+	ProjectIds []string     `json:"project_ids" key:"关联项目" parser:"link_record_ids"`
+	Projects   []*RecordRef `json:"projects" key:"负责项目"`
+	OwnedIds   []string     `json:"owned_ids" key:"负责项目,readonly" parser:"link_record_ids"`
+	Department *RecordRef   `json:"department" key:"所属部门"`
+}
+
+func TestParseLinkRecords(t *testing.T) {
+	record := &LinkRecord{}
+	assert.Nil(t, maparser.Parse(record, loadFixture(t, "link_fields.json")))
+
+	assert.Equal(t, []string{"recuS0aUa4", "recuS0aUb7"}, record.ProjectIds)
+	assert.Equal(t, []*RecordRef{
+		{RecordID: "recuS0aUa4", TableID: "tblq3Vv2c1", Text: "官网改版"},
+		{RecordID: "recuS0aUb7", TableID: "tblq3Vv2c1", Text: "数据迁移"},
+	}, record.Projects)
+	assert.Equal(t, []string{"recuS0aUa4", "recuS0aUb7"}, record.OwnedIds)
+	assert.Equal(t, &RecordRef{RecordID: "recuS0aDe1", TableID: "tblq3Vv2d2", Text: "研发部"}, record.Department)
+
+	m, err := maparser.Encode(&LinkRecord{
+		ProjectIds: record.ProjectIds,
+		Department: record.Department,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"关联项目": []string{"recuS0aUa4", "recuS0aUb7"},
+		"所属部门": []string{"recuS0aDe1"},
+	}, m)
+
+	ids, err := ParseRecordIds([]any{"rec1", "rec2"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"rec1", "rec2"}, ids)
+
+	_, err = ParseRecordRefs([]any{map[string]any{"text": "x"}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,330 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+// optionSet is the label -> value mapping of a registered option type.
+type optionSet struct {
+	values map[string]reflect.Value
+	labels map[any]string
+}
+
+// optionsKey is the parser attribute key of the option set of a type.
+type optionsKey struct {
+	t reflect.Type
+}
+
+// RegisterOptions registers the label -> value mapping of the select options parsed into type T
+// into the default parser, see RegisterOptionsWith.
+func RegisterOptions[T comparable](options map[string]T) {
+	RegisterOptionsWith(maparser.Default(), options)
+}
+
+// RegisterOptionsWith registers the label -> value mapping of the select options parsed into type T
+// into p, so that single_select and multi_select fields of T, *T and []T get typed values, e.g.
+//
+//	RegisterOptionsWith(p, map[string]Status{"进行中": StatusDoing, "已完成": StatusDone})
+//
+// Fields of a named type T, *T and []T get the parsers without parser tag.
+// A value with several labels is encoded with the first label in sorted order.
+func RegisterOptionsWith[T comparable](p *maparser.Parser, options map[string]T) {
+	set := &optionSet{
+		values: make(map[string]reflect.Value, len(options)),
+		labels: make(map[any]string, len(options)),
+	}
+
+	labels := make([]string, 0, len(options))
+	for label := range options {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		value := options[label]
+		set.values[label] = reflect.ValueOf(value)
+		if _, ok := set.labels[value]; !ok {
+			set.labels[value] = label
+		}
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	p.SetAttr(optionsKey{t}, set)
+
+	if t.PkgPath() != "" {
+		p.SetTypeParser(t, "single_select")
+		p.SetTypeParser(reflect.PointerTo(t), "single_select")
+		p.SetTypeParser(reflect.SliceOf(t), "multi_select")
+	}
+}
+
+func loadOptionSet(p *maparser.Parser, t reflect.Type) (*optionSet, bool) {
+	set, ok := p.Attr(optionsKey{t})
+	if !ok {
+		return nil, false
+	}
+
+	return set.(*optionSet), true
+}
+
+// ParseOption returns the label of a single select value, the first one of an array.
+func ParseOption(val any) (string, error) {
+	labels, err := ParseOptions(val)
+	if err != nil || len(labels) == 0 {
+		return "", err
+	}
+
+	return labels[0], nil
+}
+
+// ParseOptions returns the labels of a multi select value.
+func ParseOptions(val any) ([]string, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		return []string{v}, nil
+	case []string:
+		return v, nil
+	case []any:
+		labels := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := parseMapTextField(item)
+			if err != nil {
+				return nil, fmt.Errorf("ParseOptions: %v", err)
+			}
+			labels = append(labels, s)
+		}
+		return labels, nil
+	default:
+		return nil, fmt.Errorf("ParseOptions: invalid type %T", val)
+	}
+}
+
+// optionValue returns the value of label for type t. A string type without registered options
+// gets the label itself. An unknown label gets the value of the `fallback` argument label,
+// or is skipped with false if the argument is empty.
+func optionValue(p *maparser.Parser, t reflect.Type, label string, args maparser.Args) (reflect.Value, bool, error) {
+	set, ok := loadOptionSet(p, t)
+	if !ok {
+		if t.Kind() == reflect.String {
+			return reflect.ValueOf(label).Convert(t), true, nil
+		}
+		return reflect.Value{}, false, fmt.Errorf("no options registered for type %s", t)
+	}
+
+	if value, found := set.values[label]; found {
+		return value, true, nil
+	}
+
+	fallback, hasFallback := args["fallback"]
+	if !hasFallback {
+		return reflect.Value{}, false, fmt.Errorf("unknown option %s of %s", label, t)
+	}
+
+	if fallback == "" {
+		return reflect.Value{}, false, nil
+	}
+
+	value, found := set.values[fallback]
+	if !found {
+		return reflect.Value{}, false, fmt.Errorf("unknown fallback option %s of %s", fallback, t)
+	}
+
+	return value, true, nil
+}
+
+// optionLabel returns the label of the option value v, the reverse of optionValue.
+func optionLabel(p *maparser.Parser, v reflect.Value) (string, error) {
+	set, ok := loadOptionSet(p, v.Type())
+	if !ok {
+		if v.Kind() == reflect.String {
+			return v.String(), nil
+		}
+		return "", fmt.Errorf("no options registered for type %s", v.Type())
+	}
+
+	label, found := set.labels[v.Interface()]
+	if !found {
+		return "", fmt.Errorf("unknown option value %v of %s", v.Interface(), v.Type())
+	}
+
+	return label, nil
+}
+
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func SingleSelectValueParser(val any) (any, error) {
+	return ParseOption(val)
+}
+
+// SingleSelectParsers binds the single_select parsers to the options registered into p.
+func SingleSelectParsers(p *maparser.Parser) (maparser.ValueParser, maparser.ArgsFieldParser) {
+	return SingleSelectValueParser, NewSingleSelectFieldParser(p)
+}
+
+// MultiSelectParsers binds the multi_select parsers to the options registered into p.
+func MultiSelectParsers(p *maparser.Parser) (maparser.ValueParser, maparser.ArgsFieldParser) {
+	return MultiSelectValueParser, NewMultiSelectFieldParser(p)
+}
+
+// NewSingleSelectFieldParser returns the parser setting the label of a single select field into a string field,
+// or its value into a field of a type registered into p with RegisterOptionsWith.
+func NewSingleSelectFieldParser(p *maparser.Parser) maparser.ArgsFieldParser {
+	return func(dest reflect.Value, val any, args maparser.Args) error {
+		return setSingleSelect(p, dest, val, args)
+	}
+}
+
+func setSingleSelect(p *maparser.Parser, dest reflect.Value, val any, args maparser.Args) error {
+	label, err := ParseOption(val)
+	if err != nil || label == "" {
+		return err
+	}
+
+	value, ok, err := optionValue(p, elemType(dest.Type()), label, args)
+	if err != nil || !ok {
+		return err
+	}
+
+	maparser.AllocIndirect(dest).Set(value)
+	return nil
+}
+
+func MultiSelectValueParser(val any) (any, error) {
+	return ParseOptions(val)
+}
+
+// NewMultiSelectFieldParser returns the parser setting the labels of a multi select field into a []string field,
+// the values into a slice of a type registered into p with RegisterOptionsWith,
+// or the labels joined with the `sep` argument, "," by default, into a string field.
+func NewMultiSelectFieldParser(p *maparser.Parser) maparser.ArgsFieldParser {
+	return func(dest reflect.Value, val any, args maparser.Args) error {
+		return setMultiSelect(p, dest, val, args)
+	}
+}
+
+func setMultiSelect(p *maparser.Parser, dest reflect.Value, val any, args maparser.Args) error {
+	labels, err := ParseOptions(val)
+	if err != nil || labels == nil {
+		return err
+	}
+
+	t := elemType(dest.Type())
+	switch t.Kind() {
+	case reflect.String:
+		maparser.AllocIndirect(dest).SetString(strings.Join(labels, args.Get("sep", ",")))
+		return nil
+	case reflect.Slice:
+	default:
+		return fmt.Errorf("MultiSelectFieldParser: invalid type %s", t)
+	}
+
+	values := reflect.MakeSlice(t, 0, len(labels))
+	for _, label := range labels {
+		value, ok, err := optionValue(p, t.Elem(), label, args)
+		if err != nil {
+			return err
+		}
+		if ok {
+			values = reflect.Append(values, value)
+		}
+	}
+
+	maparser.AllocIndirect(dest).Set(values)
+	return nil
+}
+
+// NewSingleSelectFieldEncoder returns the encoder of a string or registered option field as its label,
+// a zero value without label is left out.
+func NewSingleSelectFieldEncoder(p *maparser.Parser) maparser.ArgsFieldEncoder {
+	return func(src reflect.Value, _ maparser.Args) (any, error) {
+		return encodeSingleSelect(p, src)
+	}
+}
+
+func encodeSingleSelect(p *maparser.Parser, src reflect.Value) (any, error) {
+	v := reflect.Indirect(src)
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	label, err := optionLabel(p, v)
+	if label == "" && v.IsZero() {
+		return nil, nil
+	}
+
+	return label, err
+}
+
+// NewMultiSelectFieldEncoder returns the encoder of a slice of strings or registered options as the label array,
+// a string field is split with the `sep` argument.
+func NewMultiSelectFieldEncoder(p *maparser.Parser) maparser.ArgsFieldEncoder {
+	return func(src reflect.Value, args maparser.Args) (any, error) {
+		return encodeMultiSelect(p, src, args)
+	}
+}
+
+func encodeMultiSelect(p *maparser.Parser, src reflect.Value, args maparser.Args) (any, error) {
+	v := reflect.Indirect(src)
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if v.String() == "" {
+			return nil, nil
+		}
+		return strings.Split(v.String(), args.Get("sep", ",")), nil
+	case reflect.Slice:
+	default:
+		return nil, fmt.Errorf("MultiSelectFieldEncoder: invalid type %s", v.Type())
+	}
+
+	if v.Len() == 0 {
+		return nil, nil
+	}
+
+	labels := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		label, err := optionLabel(p, v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+
+	return labels, nil
+}
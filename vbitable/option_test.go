@@ -0,0 +1,154 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+type TaskStatus int
+
+const (
+	TaskStatusUnknown TaskStatus = iota
+	TaskStatusTodo
+	TaskStatusDoing
+	TaskStatusDone
+)
+
+type TaskTag string
+
+func init() {
+	RegisterOptions(map[string]TaskStatus{
+		"待办":  TaskStatusTodo,
+		"进行中": TaskStatusDoing,
+		"已完成": TaskStatusDone,
+		"完成":  TaskStatusDone,
+		"其他":  TaskStatusUnknown,
+	})
+	RegisterOptions(map[string]TaskTag{"紧急": "urgent", "重要": "important"})
+}
+
+type OptionRecord struct {
+	Status     TaskStatus   `json:"status" key:"状态"`
+	StatusPtr  *TaskStatus  `json:"status_ptr" key:"状态指针"`
+	Fallback   TaskStatus   `json:"fallback" key:"回退" parser:"single_select(fallback=其他)"`
+	Label      string       `json:"label" key:"标签" parser:"single_select"`
+	Tags       []TaskTag    `json:"tags" key:"标记"`
+	Labels     []string     `json:"labels" key:"多选" parser:"multi_select"`
+	Joined     string       `json:"joined" key:"拼接" parser:"multi_select(sep=;)"`
+	Statuses   []TaskStatus `json:"statuses" key:"多状态" parser:"multi_select(fallback)"`
+	Unassigned TaskStatus   `json:"unassigned" key:"未分配"`
+}
+
+func TestParseOptions(t *testing.T) {
+	record := &OptionRecord{}
+	err := maparser.Parse(record, map[string]any{
+		"状态":   "进行中",
+		"状态指针": []any{"完成"},
+		"回退":   "已取消",
+		"标签":   "自定义",
+		"标记":   []any{"重要", "紧急"},
+		"多选":   []any{"a", "b"},
+		"拼接":   []any{"a", "b"},
+		"多状态":  []any{"待办", "已取消", "已完成"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, TaskStatusDoing, record.Status)
+	assert.Equal(t, TaskStatusDone, *record.StatusPtr)
+	assert.Equal(t, TaskStatusUnknown, record.Fallback)
+	assert.Equal(t, "自定义", record.Label)
+	assert.Equal(t, []TaskTag{"important", "urgent"}, record.Tags)
+	assert.Equal(t, []string{"a", "b"}, record.Labels)
+	assert.Equal(t, "a;b", record.Joined)
+	assert.Equal(t, []TaskStatus{TaskStatusTodo, TaskStatusDone}, record.Statuses)
+
+	m, err := maparser.Encode(record)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"状态":   "进行中",
+		"状态指针": "完成",
+		"回退":   "其他",
+		"标签":   "自定义",
+		"标记":   []string{"重要", "紧急"},
+		"多选":   []string{"a", "b"},
+		"拼接":   []string{"a", "b"},
+		"多状态":  []string{"待办", "完成"},
+		"未分配":  "其他",
+	}, m)
+
+	err = maparser.Parse(&OptionRecord{}, map[string]any{"状态": "已取消"})
+	assert.ErrorContains(t, err, "unknown option 已取消")
+
+	err = maparser.Parse(&OptionRecord{}, map[string]any{"标记": []any{"普通"}})
+	assert.ErrorContains(t, err, "unknown option 普通")
+
+	v, err := maparser.ParseValue("multi_select", []any{"a", "b"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, v)
+}
+
+type UnregisteredOption int
+
+type UnregisteredOptionRecord struct {
+	Option UnregisteredOption `json:"option" key:"选项" parser:"single_select"`
+}
+
+func TestParseUnregisteredOption(t *testing.T) {
+	err := maparser.Parse(&UnregisteredOptionRecord{}, map[string]any{"选项": "a"})
+	assert.ErrorContains(t, err, "no options registered")
+}
+
+type TenantStatus int
+
+const (
+	TenantStatusOpen TenantStatus = iota + 1
+	TenantStatusClosed
+)
+
+type TenantOptionRecord struct {
+	Status TenantStatus `json:"status" key:"状态"`
+}
+
+func TestParseOptionsWithParser(t *testing.T) {
+	p := maparser.New()
+	RegisterParsers(p)
+	RegisterOptionsWith(p, map[string]TenantStatus{"打开": TenantStatusOpen, "已完成": TenantStatusClosed})
+
+	record := &TenantOptionRecord{}
+	assert.Nil(t, p.Parse(record, map[string]any{"状态": "已完成"}))
+	assert.Equal(t, TenantStatusClosed, record.Status)
+
+	m, err := p.Encode(record)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"状态": "已完成"}, m)
+
+	// a copied parser keeps the options, registering more does not change the source.
+	copied := maparser.New(maparser.WithParsersFrom(p))
+	RegisterOptionsWith(copied, map[string]TenantStatus{"关闭": TenantStatusClosed})
+	record = &TenantOptionRecord{}
+	assert.Nil(t, copied.Parse(record, map[string]any{"状态": "关闭"}))
+	assert.Equal(t, TenantStatusClosed, record.Status)
+	assert.NotNil(t, p.Parse(&TenantOptionRecord{}, map[string]any{"状态": "关闭"}))
+
+	// the default parser has no options of the type.
+	err = maparser.Parse(&TenantOptionRecord{}, map[string]any{"状态": "已完成"})
+	assert.NotNil(t, err)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/vogo/vlarksdk/maparser"
@@ -81,6 +82,11 @@ func MapFieldTextValueParser(val any) (any, error) {
 }
 
 func ParseMapFieldText(val any) (string, error) {
+	return ParseMapFieldTextSep(val, ",")
+}
+
+// ParseMapFieldTextSep joins the text segments with sep.
+func ParseMapFieldTextSep(val any, sep string) (string, error) {
 	if val == nil {
 		return "", nil
 	}
@@ -108,7 +114,7 @@ func ParseMapFieldText(val any) (string, error) {
 		textArr = append(textArr, s)
 	}
 
-	return strings.Join(textArr, ","), nil
+	return strings.Join(textArr, sep), nil
 }
 
 func parseMapTextField(v interface{}) (string, error) {
@@ -187,7 +193,7 @@ func MapFieldAttachParser(dest reflect.Value, val any) error {
 	if err != nil {
 		return err
 	}
-	maparser.SetValue(dest, s)
+	maparser.AllocIndirect(dest).SetString(s)
 	return nil
 }
 
@@ -201,29 +207,45 @@ func MapFieldTextLinkParser(dest reflect.Value, val any) error {
 		return err
 	}
 
-	dest.SetString(s)
+	maparser.AllocIndirect(dest).SetString(s)
 	return nil
 }
 
 func MapFieldTextFieldParser(dest reflect.Value, val any) error {
+	return MapFieldTextArgsFieldParser(dest, val, nil)
+}
+
+// MapFieldTextArgsFieldParser joins the text segments with the `sep` argument, "," by default.
+func MapFieldTextArgsFieldParser(dest reflect.Value, val any, args maparser.Args) error {
 	if val == nil {
 		return nil
 	}
 
-	s, err := ParseMapFieldText(val)
+	s, err := ParseMapFieldTextSep(val, args.Get("sep", ","))
 	if err != nil {
 		return err
 	}
 
-	dest.SetString(s)
+	maparser.AllocIndirect(dest).SetString(s)
 	return nil
 }
 
 func MapFieldTextDateParser(dest reflect.Value, val any) error {
+	return MapFieldTextDateArgsParser(dest, val, nil)
+}
+
+// MapFieldTextDateArgsParser parses a timestamp or a date text, the `format` argument sets the
+// date layout instead of 2006/01/02 and 2006-01-02, the `tz` argument sets the time zone.
+func MapFieldTextDateArgsParser(dest reflect.Value, val any, args maparser.Args) error {
 	if val == nil {
 		return nil
 	}
 
+	loc, err := argsLocation(args)
+	if err != nil {
+		return err
+	}
+
 	var timestamp int64
 	switch v := val.(type) {
 	case int:
@@ -238,7 +260,7 @@ func MapFieldTextDateParser(dest reflect.Value, val any) error {
 		timestamp = int64(v)
 	}
 	if timestamp > 0 {
-		date := time.UnixMilli(timestamp)
+		date := time.UnixMilli(timestamp).In(loc)
 		maparser.SetValue(dest, date)
 		return nil
 	}
@@ -248,31 +270,102 @@ func MapFieldTextDateParser(dest reflect.Value, val any) error {
 		return err
 	}
 
-	date, err := time.Parse("2006/01/02", s)
-	if err != nil {
-		date, err = time.Parse("2006-01-02", s)
-		if err != nil {
-			return fmt.Errorf("MapFieldTextDateParser: invalid time format %s, error:%s", s, err)
+	layouts := []string{"2006/01/02", "2006-01-02"}
+	if format, ok := args["format"]; ok {
+		layouts = []string{format}
+	}
+
+	// date texts are in UTC unless a time zone is given.
+	if _, ok := args["tz"]; !ok {
+		loc = time.UTC
+	}
+
+	var date time.Time
+	for _, layout := range layouts {
+		if date, err = time.ParseInLocation(layout, s, loc); err == nil {
+			break
 		}
 	}
+	if err != nil {
+		return fmt.Errorf("MapFieldTextDateParser: invalid time format %s, error:%s", s, err)
+	}
 
 	maparser.SetValue(dest, date)
 	return nil
 }
 
+// locationCache caches the time zones of the `tz` argument, name -> *time.Location.
+var locationCache sync.Map
+
+// argsLocation returns the time zone of the `tz` argument, time.Local by default.
+func argsLocation(args maparser.Args) (*time.Location, error) {
+	name, ok := args["tz"]
+	if !ok {
+		return time.Local, nil
+	}
+
+	if loc, cached := locationCache.Load(name); cached {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %s: %v", name, err)
+	}
+
+	locationCache.Store(name, loc)
+
+	return loc, nil
+}
+
+// validateLocationArgs checks the `tz` argument.
+func validateLocationArgs(args maparser.Args) error {
+	_, err := argsLocation(args)
+	return err
+}
+
 func TimestampFieldParser(dest reflect.Value, val any) error {
+	return TimestampArgsFieldParser(dest, val, nil)
+}
+
+// TimestampArgsFieldParser parses a millisecond timestamp in the time zone of the `tz` argument.
+func TimestampArgsFieldParser(dest reflect.Value, val any, args maparser.Args) error {
 	if val == nil {
 		return nil
 	}
 
+	loc, err := argsLocation(args)
+	if err != nil {
+		return err
+	}
+
 	t, err := ParseTimestampValue(val)
 	if err != nil {
 		return err
 	}
-	maparser.SetValue(dest, t)
+	maparser.SetValue(dest, t.In(loc))
 	return nil
 }
 
+// TimestampFieldEncoder encodes a time field as a millisecond timestamp, zero time is left out.
+func TimestampFieldEncoder(src reflect.Value) (any, error) {
+	val := maparser.GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	t, ok := val.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("TimestampFieldEncoder: invalid type %T", val)
+	}
+
+	if t.IsZero() {
+		return nil, nil
+	}
+
+	return t.UnixMilli(), nil
+}
+
 func TimestampValueParser(val any) (any, error) {
 	return ParseTimestampValue(val)
 }
@@ -336,12 +429,15 @@ func LarkDaysParser(dest reflect.Value, val any) error {
 	return nil
 }
 
+// FuncIntParser sets a formula number into an int field, truncating fractions and ignoring invalid values.
+//
+// Deprecated: use the formula parser, which reports invalid values and sets any field type.
 func FuncIntParser(dest reflect.Value, val any) error {
 	if val == nil {
 		return nil
 	}
 
-	_ = maparser.IntFieldParser(dest, val)
+	_ = maparser.SetNumber(dest, val, maparser.RoundTrunc)
 
 	return nil
 }
@@ -0,0 +1,192 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+type DateRecord struct {
+	Updated  time.Time `json:"updated" key:"更新时间" parser:"timestamp(tz=Asia/Shanghai)"`
+	Day      time.Time `json:"day" key:"日期" parser:"map_field_text_date(format=2006.01.02)"`
+	LocalDay time.Time `json:"local_day" key:"本地日期" parser:"map_field_text_date(tz=Asia/Shanghai)"`
+	Remark   string    `json:"remark" key:"备注" parser:"map_field_text(sep=;)"`
+}
+
+func TestParseArgs(t *testing.T) {
+	record := &DateRecord{}
+	err := maparser.Parse(record, map[string]any{
+		"更新时间": float64(1700000000000),
+		"日期":   "2024.05.01",
+		"本地日期": "2024/05/01",
+		"备注": []any{
+			map[string]any{"text": "a", "type": "text"},
+			map[string]any{"text": "b", "type": "text"},
+		},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, "Asia/Shanghai", record.Updated.Location().String())
+	assert.Equal(t, int64(1700000000000), record.Updated.UnixMilli())
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), record.Day)
+	assert.Equal(t, "2024-05-01 00:00:00 +0800 CST", record.LocalDay.String())
+	assert.Equal(t, "a;b", record.Remark)
+
+	// invalid arguments fail building the config, before any record is parsed.
+	type InvalidTz struct {
+		Updated time.Time `json:"updated" key:"更新时间" parser:"timestamp(tz=Asia/Shanghia)"`
+	}
+	err = maparser.Parse(&InvalidTz{}, map[string]any{"更新时间": float64(1700000000000)})
+	assert.ErrorContains(t, err, "vbitable.InvalidTz.Updated: parser timestamp: invalid time zone Asia/Shanghia")
+}
+
+type InferRecord struct {
+	Updated time.Time   `json:"updated" key:"更新时间"`
+	Owner   *LarkUser   `json:"owner" key:"负责人"`
+	Members []*LarkUser `json:"members" key:"成员"`
+	Files   []*FileInfo `json:"files" key:"附件"`
+}
+
+func TestInferParser(t *testing.T) {
+	user := map[string]any{"id": "ou_1", "name": "vogo", "email": "vogo@example.com"}
+	record := &InferRecord{}
+	err := maparser.Parse(record, map[string]any{
+		"更新时间": float64(1700000000000),
+		"负责人":  []any{user},
+		"成员":   []any{user, user},
+		"附件": []any{map[string]any{
+			"file_token": "token", "name": "a.png", "size": float64(1), "tmp_url": "t", "type": "image/png", "url": "u",
+		}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1700000000000), record.Updated.UnixMilli())
+	assert.Equal(t, "ou_1", record.Owner.OpenId)
+	assert.Len(t, record.Members, 2)
+	assert.Equal(t, "token", record.Files[0].FileToken)
+}
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, maparser.Validate[DateRecord]())
+	assert.Nil(t, maparser.Validate[InferRecord]())
+
+	type Invalid struct {
+		Owner string `json:"owner" key:"负责人" parser:"single_user"`
+	}
+	var typeErr *maparser.FieldTypeError
+	assert.ErrorAs(t, maparser.Validate[Invalid](), &typeErr)
+	assert.Equal(t, "single_user", typeErr.Parser)
+	assert.Equal(t, "*vbitable.LarkUser", typeErr.Expected.String())
+
+	type InvalidIDType struct {
+		Owner *LarkUser `json:"owner" key:"负责人" parser:"single_user(id_type=email)"`
+	}
+	assert.ErrorContains(t, maparser.Validate[InvalidIDType](), "parser single_user: invalid user id type email")
+
+	type InvalidRound struct {
+		Stars int `json:"stars" key:"评分" parser:"rating(round=up)"`
+	}
+	assert.ErrorContains(t, maparser.Validate[InvalidRound](), "parser rating: invalid round mode up")
+
+	type InvalidParser struct {
+		Name string `json:"name" key:"名称" parser:"unknown_parser"`
+	}
+	assert.ErrorContains(t, maparser.Validate[InvalidParser](), "vbitable.InvalidParser.Name: invalid parser unknown_parser")
+
+	// the timestamp parser takes no date string, so the default is reported by Validate.
+	type InvalidDefault struct {
+		Date time.Time `json:"date" key:"日期" default:"2024-01-01"`
+	}
+	assert.ErrorContains(t, maparser.Validate[InvalidDefault](), `InvalidDefault.Date: invalid default "2024-01-01"`)
+}
+
+func TestParseMap(t *testing.T) {
+	m, err := maparser.ParseMap(map[string]any{
+		"更新时间": float64(1700000000000),
+		"负责人":  []any{map[string]any{"id": "ou_1", "name": "vogo", "email": "vogo@example.com"}},
+		"备注":   []any{map[string]any{"text": "a", "type": "text"}},
+	}, map[string]string{
+		"更新时间": "timestamp",
+		"负责人":  "single_user_name",
+		"备注":   "map_field_text",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1700000000000), m["更新时间"].(time.Time).UnixMilli())
+	assert.Equal(t, "vogo", m["负责人"])
+	assert.Equal(t, "a", m["备注"])
+}
+
+type FuncIntRecord struct {
+	N int `json:"n" key:"n" parser:"func_int"`
+}
+
+func TestFuncIntParser(t *testing.T) {
+	record := &FuncIntRecord{}
+	assert.Nil(t, maparser.Parse(record, map[string]any{"n": 3.5}))
+	assert.Equal(t, 3, record.N)
+
+	record = &FuncIntRecord{}
+	assert.Nil(t, maparser.Parse(record, map[string]any{"n": "abc"}))
+	assert.Equal(t, 0, record.N)
+}
+
+type EncodeRecord struct {
+	Updated  time.Time   `json:"updated" key:"更新时间"`
+	Created  *time.Time  `json:"created" key:"创建时间"`
+	Files    []*FileInfo `json:"files" key:"附件"`
+	OwnerId  string      `json:"owner_id" key:"负责人" parser:"single_user_id"`
+	Reviewed time.Time   `json:"reviewed" key:"审核时间" parser:"timestamp"`
+}
+
+func TestEncodeFields(t *testing.T) {
+	created := time.UnixMilli(1600000000000)
+	m, err := maparser.Encode(&EncodeRecord{
+		Updated: time.UnixMilli(1700000000000).In(time.UTC),
+		Created: &created,
+		Files:   []*FileInfo{{FileToken: "boxcn1", Name: "a.png"}, nil, {FileToken: "boxcn2"}},
+		OwnerId: "ou_1",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"更新时间": int64(1700000000000),
+		"创建时间": int64(1600000000000),
+		"附件":   []map[string]any{{"file_token": "boxcn1"}, {"file_token": "boxcn2"}},
+		"负责人":  []map[string]any{{"id": "ou_1"}},
+	}, m)
+
+	// zero times, empty attachments and empty ids are left out.
+	m, err = maparser.Encode(&EncodeRecord{Files: []*FileInfo{}})
+	assert.Nil(t, err)
+	assert.Empty(t, m)
+
+	// the encoded fields parse back.
+	record := &EncodeRecord{}
+	assert.Nil(t, maparser.Parse(record, map[string]any{
+		"更新时间": float64(1700000000000),
+		"附件": []any{map[string]any{
+			"file_token": "boxcn1", "name": "a.png", "size": float64(1024), "tmp_url": "", "type": "image/png", "url": "",
+		}},
+		"负责人": []any{map[string]any{"id": "ou_1", "name": "vogo"}},
+	}))
+	assert.Equal(t, int64(1700000000000), record.Updated.UnixMilli())
+	assert.Equal(t, "boxcn1", record.Files[0].FileToken)
+	assert.Equal(t, "ou_1", record.OwnerId)
+}
@@ -0,0 +1,230 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+// segment types of a text field.
+const (
+	SegmentText    = "text"
+	SegmentURL     = "url"
+	SegmentMention = "mention"
+)
+
+// mention types of a mention segment.
+const (
+	MentionUser    = "User"
+	MentionDoc     = "Doc"
+	MentionDocx    = "Docx"
+	MentionSheet   = "Sheet"
+	MentionBitable = "Bitable"
+)
+
+// TextSegment is a segment of a text field: a plain text, a url, or a mention of a user or document.
+type TextSegment struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+	Link string `json:"link,omitempty"`
+
+	// MentionType is the type of the mentioned user or document, e.g. User or Docx.
+	MentionType string `json:"mentionType,omitempty"`
+
+	// Token is the open id of a mentioned user, or the token of a mentioned document.
+	Token string `json:"token,omitempty"`
+
+	// Name is the name of a mentioned user.
+	Name string `json:"name,omitempty"`
+}
+
+// IsUserMention reports whether the segment mentions a user.
+func (s *TextSegment) IsUserMention() bool {
+	return s.Type == SegmentMention && s.MentionType == MentionUser
+}
+
+// RichText is the segments of a text field, keeping links and mentions which the string parsers drop.
+type RichText []*TextSegment
+
+// mapString returns the string of key in m, or an empty string if it is absent or of another type.
+func mapString(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// ParseRichText parses the segment array of a text field, a plain string is a single text segment.
+func ParseRichText(val any) (RichText, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		return RichText{{Type: SegmentText, Text: v}}, nil
+	case []any:
+		text := make(RichText, 0, len(v))
+		for _, item := range v {
+			segment, err := parseTextSegment(item)
+			if err != nil {
+				return nil, err
+			}
+			text = append(text, segment)
+		}
+		return text, nil
+	default:
+		return nil, fmt.Errorf("ParseRichText: invalid type %T", val)
+	}
+}
+
+func parseTextSegment(item any) (*TextSegment, error) {
+	switch v := item.(type) {
+	case string:
+		return &TextSegment{Type: SegmentText, Text: v}, nil
+	case map[string]any:
+		segment := &TextSegment{
+			Type:        mapString(v, "type"),
+			Text:        mapString(v, "text"),
+			Link:        mapString(v, "link"),
+			MentionType: mapString(v, "mentionType"),
+			Token:       mapString(v, "token"),
+			Name:        mapString(v, "name"),
+		}
+		if segment.Type == "" {
+			segment.Type = SegmentText
+		}
+		return segment, nil
+	default:
+		return nil, fmt.Errorf("ParseRichText: invalid segment type %T", item)
+	}
+}
+
+// PlainText joins the text of the segments.
+func (r RichText) PlainText() string {
+	var b strings.Builder
+	for _, s := range r {
+		b.WriteString(s.Text)
+	}
+
+	return b.String()
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+)
+
+var markdownLinkEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
+
+// safeLink reports whether a link is rendered, only http, https and mailto links are,
+// which keeps links like `javascript:alert(1)` out of the output.
+func safeLink(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+// Markdown renders urls and document mentions as markdown links, escaping the text.
+// Links of other schemes than http, https and mailto render the text alone.
+func (r RichText) Markdown() string {
+	var b strings.Builder
+	for _, s := range r {
+		text := markdownEscaper.Replace(s.Text)
+		if s.Link == "" || s.IsUserMention() || !safeLink(s.Link) {
+			b.WriteString(text)
+			continue
+		}
+
+		b.WriteString("[" + text + "](" + markdownLinkEscaper.Replace(s.Link) + ")")
+	}
+
+	return b.String()
+}
+
+// HTML renders urls and document mentions as links and user mentions as spans with the open id,
+// escaping the text and converting line breaks. Links of other schemes than http, https and mailto render the text alone.
+func (r RichText) HTML() string {
+	var b strings.Builder
+	for _, s := range r {
+		text := strings.ReplaceAll(html.EscapeString(s.Text), "\n", "<br>")
+		switch {
+		case s.IsUserMention():
+			b.WriteString(`<span class="mention" data-user-id="` + html.EscapeString(s.Token) + `">` + text + "</span>")
+		case s.Link != "" && safeLink(s.Link):
+			b.WriteString(`<a href="` + html.EscapeString(s.Link) + `">` + text + "</a>")
+		default:
+			b.WriteString(text)
+		}
+	}
+
+	return b.String()
+}
+
+// Mentions returns the user mention segments.
+func (r RichText) Mentions() []*TextSegment {
+	var mentions []*TextSegment
+	for _, s := range r {
+		if s.IsUserMention() {
+			mentions = append(mentions, s)
+		}
+	}
+
+	return mentions
+}
+
+func RichTextFieldParser(dest reflect.Value, val any) error {
+	text, err := ParseRichText(val)
+	if err != nil || text == nil {
+		return err
+	}
+
+	maparser.SetValue(dest, text)
+	return nil
+}
+
+// RichTextFieldEncoder encodes a rich text as its plain text, which is what a text field accepts.
+func RichTextFieldEncoder(src reflect.Value) (any, error) {
+	val := maparser.GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	text, ok := val.(RichText)
+	if !ok {
+		return nil, fmt.Errorf("RichTextFieldEncoder: invalid type %T", val)
+	}
+
+	if len(text) == 0 {
+		return nil, nil
+	}
+
+	return text.PlainText(), nil
+}
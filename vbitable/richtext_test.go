@@ -0,0 +1,87 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+var richTextValue = []any{
+	map[string]any{"type": "text", "text": "请 "},
+	map[string]any{
+		"type": "mention", "mentionType": "User", "text": "@张三", "token": "ou_1", "name": "张三",
+		"mentionNotify": false,
+	},
+	map[string]any{"type": "text", "text": " 查看 *草稿* <v2>\n"},
+	map[string]any{
+		"type": "mention", "mentionType": "Docx", "text": "设计文档", "token": "doxcn1",
+		"link": "https://example.feishu.cn/docx/doxcn1",
+	},
+	map[string]any{"type": "text", "text": " 和 "},
+	map[string]any{"type": "url", "text": "官网", "link": "https://example.com/a b"},
+}
+
+type RichTextRecord struct {
+	Content RichText  `json:"content" key:"内容"`
+	Plain   *RichText `json:"plain" key:"纯文本" parser:"rich_text"`
+	Empty   RichText  `json:"empty" key:"空"`
+}
+
+func TestParseRichText(t *testing.T) {
+	record := &RichTextRecord{}
+	err := maparser.Parse(record, map[string]any{
+		"内容":  richTextValue,
+		"纯文本": "hello",
+		"空":   []any{},
+	})
+	assert.Nil(t, err)
+
+	assert.Len(t, record.Content, 6)
+	assert.Equal(t, &TextSegment{
+		Type: SegmentMention, MentionType: MentionUser, Text: "@张三", Token: "ou_1", Name: "张三",
+	}, record.Content[1])
+	assert.Equal(t, RichText{{Type: SegmentText, Text: "hello"}}, *record.Plain)
+	assert.Empty(t, record.Empty)
+
+	assert.Equal(t, "请 @张三 查看 *草稿* <v2>\n设计文档 和 官网", record.Content.PlainText())
+	assert.Equal(t, `请 @张三 查看 \*草稿\* <v2>`+"\n"+
+		`[设计文档](https://example.feishu.cn/docx/doxcn1) 和 [官网](https://example.com/a%20b)`,
+		record.Content.Markdown())
+	assert.Equal(t, `请 <span class="mention" data-user-id="ou_1">@张三</span> 查看 *草稿* &lt;v2&gt;<br>`+
+		`<a href="https://example.feishu.cn/docx/doxcn1">设计文档</a> 和 <a href="https://example.com/a b">官网</a>`,
+		record.Content.HTML())
+	assert.Equal(t, []*TextSegment{record.Content[1]}, record.Content.Mentions())
+
+	m, err := maparser.Encode(record)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"内容": record.Content.PlainText(), "纯文本": "hello"}, m)
+
+	_, err = ParseRichText([]any{1})
+	assert.NotNil(t, err)
+
+	unsafe := RichText{
+		{Type: SegmentURL, Text: "点我", Link: "javascript:alert(1)"},
+		{Type: SegmentURL, Text: " 邮件", Link: "mailto:a@example.com"},
+		{Type: SegmentURL, Text: " 数据", Link: " JavaScript:alert(1)"},
+	}
+	assert.Equal(t, `点我<a href="mailto:a@example.com"> 邮件</a> 数据`, unsafe.HTML())
+	assert.Equal(t, `点我[ 邮件](mailto:a@example.com) 数据`, unsafe.Markdown())
+}
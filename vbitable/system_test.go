@@ -0,0 +1,104 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+type SystemRecord struct {
+	Groups     []*LarkGroup `json:"groups" key:"项目群"`
+	MainGroup  *LarkGroup   `json:"main_group" key:"项目群,readonly"`
+	GroupNames string       `json:"group_names" key:"项目群,readonly" parser:"group_chat"`
+	Creator    *LarkUser    `json:"creator" key:"创建人" parser:"created_user"`
+	CreatorStr string       `json:"creator_str" key:"创建人" parser:"single_user_name_email"`
+	Modifier   *LarkUser    `json:"modifier" key:"修改人" parser:"modified_user"`
+	Created    time.Time    `json:"created" key:"创建时间" parser:"created_time"`
+	Modified   time.Time    `json:"modified" key:"最后更新时间" parser:"modified_time(tz=Asia/Shanghai)"`
+	No         string       `json:"no" key:"编号" parser:"auto_number"`
+	Seq        int          `json:"seq" key:"编号" parser:"auto_number"`
+}
+
+func TestParseSystemFields(t *testing.T) {
+	record := &SystemRecord{}
+	assert.Nil(t, maparser.Parse(record, loadFixture(t, "system_fields.json")))
+
+	group := &LarkGroup{ID: "oc_1a2b3c", Name: "官网改版项目群", AvatarURL: "https://example.com/avatar/group.png"}
+	assert.Len(t, record.Groups, 2)
+	assert.Equal(t, group, record.Groups[0])
+	assert.Equal(t, group, record.MainGroup)
+	assert.Equal(t, "官网改版项目群,运维值班群", record.GroupNames)
+	assert.Equal(t, &LarkUser{ID: "ou_1", IDType: OpenID, Name: "vogo", EnName: "vogo", OpenId: "ou_1"}, record.Creator)
+	assert.Equal(t, "vogo", record.CreatorStr)
+	assert.Equal(t, "lark@example.com", record.Modifier.Email)
+	assert.Equal(t, int64(1700000000000), record.Created.UnixMilli())
+	assert.Equal(t, "Asia/Shanghai", record.Modified.Location().String())
+	assert.Equal(t, "NO-0042", record.No)
+	assert.Equal(t, 42, record.Seq)
+
+	m, err := maparser.Encode(record)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"项目群": []map[string]any{{"id": "oc_1a2b3c"}, {"id": "oc_4d5e6f"}},
+	}, m)
+
+	// fields writing the same key fail instead of overwriting each other.
+	type SharedKeyRecord struct {
+		Groups    []*LarkGroup `json:"groups" key:"项目群"`
+		MainGroup *LarkGroup   `json:"main_group" key:"项目群"`
+	}
+	_, err = maparser.Encode(&SharedKeyRecord{Groups: record.Groups, MainGroup: record.MainGroup})
+	assert.ErrorContains(t, err, "fields Groups and MainGroup write key 项目群")
+
+	assert.NotNil(t, maparser.Parse(&SystemRecord{}, map[string]any{"编号": "NO-"}))
+	assert.NotNil(t, maparser.Parse(&SystemRecord{}, map[string]any{"项目群": []any{map[string]any{"name": "x"}}}))
+}
+
+func TestParseUserTolerant(t *testing.T) {
+	u, err := ParseUser([]any{})
+	assert.Nil(t, err)
+	assert.Nil(t, u)
+
+	u, err = ParseUser(map[string]any{"id": "ou_1"})
+	assert.Nil(t, err)
+	assert.Equal(t, &LarkUser{ID: "ou_1", IDType: OpenID, OpenId: "ou_1"}, u)
+
+	_, err = ParseUser(map[string]any{"id": float64(1)})
+	assert.NotNil(t, err)
+
+	_, err = ParseUser([]any{"ou_1"})
+	assert.NotNil(t, err)
+
+	_, err = ParseUserName([]any{map[string]any{"name": float64(1)}})
+	assert.NotNil(t, err)
+
+	_, err = ParseUserEmail(map[string]any{"id": "ou_1"})
+	assert.NotNil(t, err)
+
+	id, err := ParseUserId([]any{})
+	assert.Nil(t, err)
+	assert.Equal(t, "", id)
+
+	users, err := ParseMultipleUsers(map[string]any{"id": "ou_1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []*LarkUser{{ID: "ou_1", IDType: OpenID, OpenId: "ou_1"}}, users)
+}
@@ -21,150 +21,103 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+
+	"github.com/vogo/vlarksdk/maparser"
 )
 
 func ParseUserNameEmail(val any) (string, error) {
-	if val == nil {
-		return "", nil
-	}
-
-	m, ok := val.(map[string]interface{})
-	if !ok {
-		var arr []interface{}
-		var mm map[string]interface{}
-
-		if arr, ok = val.([]interface{}); ok {
-			if mm, ok = arr[0].(map[string]interface{}); ok {
-				m = mm
-			}
-		}
-
-		if !ok {
-			return "", fmt.Errorf("ParseUserNameEmail: invalid type %T", val)
-		}
+	m, err := userMap(val, "ParseUserNameEmail")
+	if err != nil || m == nil {
+		return "", err
 	}
 
-	email, ok := m["email"]
+	name, ok := m["name"].(string)
 	if !ok {
-		return "", fmt.Errorf("ParseUserNameEmail: %s", "email field not found")
+		return "", fmt.Errorf("ParseUserNameEmail: %s", "name field not found")
 	}
 
-	name, ok := m["name"]
-	if !ok {
-		return "", fmt.Errorf("ParseUserNameEmail: %s", "name field not found")
+	// users without email, e.g. of created by fields, get the name only.
+	email := mapString(m, "email")
+	if email == "" {
+		return name, nil
 	}
 
-	emailPrefix, _, _ := strings.Cut(email.(string), "@")
+	emailPrefix, _, _ := strings.Cut(email, "@")
 
 	return fmt.Sprintf("%s(%s)", name, emailPrefix), nil
 }
 
 func ParseUserName(val any) (string, error) {
-	if val == nil {
-		return "", nil
-	}
-
-	m, ok := val.(map[string]interface{})
-	if !ok {
-		var arr []interface{}
-		var mm map[string]interface{}
-
-		if arr, ok = val.([]interface{}); ok {
-			if mm, ok = arr[0].(map[string]interface{}); ok {
-				m = mm
-			}
-		}
-
-		if !ok {
-			return "", fmt.Errorf("ParseUserNameEmail: invalid type %T", val)
-		}
+	m, err := userMap(val, "ParseUserName")
+	if err != nil || m == nil {
+		return "", err
 	}
 
-	name, ok := m["name"]
+	name, ok := m["name"].(string)
 	if !ok {
-		return "", fmt.Errorf("ParseUserNameEmail: %s", "name field not found")
+		return "", fmt.Errorf("ParseUserName: %s", "name field not found")
 	}
 
-	return name.(string), nil
+	return name, nil
 }
 
 func ParseUserEmail(val any) (string, error) {
-	if val == nil {
-		return "", nil
-	}
-
-	m, ok := val.(map[string]interface{})
-	if !ok {
-		var arr []interface{}
-		var mm map[string]interface{}
-
-		if arr, ok = val.([]interface{}); ok {
-			if mm, ok = arr[0].(map[string]interface{}); ok {
-				m = mm
-			}
-		}
-
-		if !ok {
-			return "", fmt.Errorf("ParseUserNameEmail: invalid type %T", val)
-		}
+	m, err := userMap(val, "ParseUserEmail")
+	if err != nil || m == nil {
+		return "", err
 	}
 
-	email, ok := m["email"]
+	email, ok := m["email"].(string)
 	if !ok {
 		return "", fmt.Errorf("ParseUserEmail: %s", "email field not found")
 	}
 
-	return email.(string), nil
+	return email, nil
 }
 
+// ParseMultipleUserUnionIds returns the ids of the users of a person field read with user_id_type=union_id.
 func ParseMultipleUserUnionIds(val any) ([]string, error) {
-	if val == nil {
-		return nil, nil
-	}
-
-	arr, ok := val.([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("ParseMultipleUserUnionIds: invalid type %T", val)
-	}
-	var users []string
-	for _, v := range arr {
-		unionId, err := ParseUserId(v)
-		if err != nil {
-			return nil, err
-		}
-		users = append(users, unionId)
+	users, err := ParseMultipleUsersAs(val, UnionID)
+	if err != nil {
+		return nil, err
 	}
 
-	return users, nil
+	return UserIDs(users), nil
 }
 
 func ParseUserId(val any) (string, error) {
-	if val == nil {
-		return "", nil
+	m, err := userMap(val, "ParseUserId")
+	if err != nil || m == nil {
+		return "", err
 	}
 
-	m, ok := val.(map[string]interface{})
+	id, ok := m["id"].(string)
 	if !ok {
-		var arr []interface{}
-		var mm map[string]interface{}
+		return "", fmt.Errorf("ParseUserId: %s", "id field not found")
+	}
 
-		if arr, ok = val.([]interface{}); ok {
-			if mm, ok = arr[0].(map[string]interface{}); ok {
-				m = mm
-			}
-		}
+	return id, nil
+}
 
-		if !ok {
-			return "", fmt.Errorf("ParseUserId: invalid type %T", val)
+// userMap returns the user object of a person field value, the first one of an array,
+// or nil for an absent value or an empty array.
+func userMap(val any, parserName string) (map[string]any, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return v, nil
+	case []any:
+		if len(v) == 0 {
+			return nil, nil
 		}
+		if m, ok := v[0].(map[string]any); ok {
+			return m, nil
+		}
+		return nil, fmt.Errorf("%s: invalid type %T", parserName, v[0])
+	default:
+		return nil, fmt.Errorf("%s: invalid type %T", parserName, val)
 	}
-
-	unionId, ok := m["id"]
-	if !ok {
-		return "", fmt.Errorf("ParseUserId: %s", "id field not found")
-	}
-
-	return unionId.(string), nil
 }
 
 func SingleUserNameEmail(dest reflect.Value, val any) error {
@@ -177,7 +130,7 @@ func SingleUserNameEmail(dest reflect.Value, val any) error {
 		return err
 	}
 
-	dest.SetString(nameEmail)
+	maparser.AllocIndirect(dest).SetString(nameEmail)
 	return nil
 }
 
@@ -191,7 +144,7 @@ func SingleUserEmail(dest reflect.Value, val any) error {
 		return err
 	}
 
-	dest.SetString(email)
+	maparser.AllocIndirect(dest).SetString(email)
 	return nil
 }
 
@@ -205,7 +158,7 @@ func SingleUserName(dest reflect.Value, val any) error {
 		return err
 	}
 
-	dest.SetString(id)
+	maparser.AllocIndirect(dest).SetString(id)
 	return nil
 }
 
@@ -219,7 +172,7 @@ func SingleUserId(dest reflect.Value, val any) error {
 		return err
 	}
 
-	dest.SetString(id)
+	maparser.AllocIndirect(dest).SetString(id)
 	return nil
 }
 
@@ -241,56 +194,171 @@ func MultipleUserNameEmail(dest reflect.Value, val any) error {
 		users = append(users, nameEmail)
 	}
 
-	dest.SetString(strings.Join(users, ","))
+	maparser.AllocIndirect(dest).SetString(strings.Join(users, ","))
 	return nil
 }
 
+// UserIDType is the type of the user ids of person fields, set by the user_id_type of the request.
+type UserIDType string
+
+const (
+	OpenID  UserIDType = "open_id"
+	UnionID UserIDType = "union_id"
+	UserID  UserIDType = "user_id"
+)
+
+// argsUserIDType returns the user id type of the `id_type` argument, open_id by default,
+// e.g. `parser:"single_user(id_type=union_id)"`.
+func argsUserIDType(args maparser.Args) (UserIDType, error) {
+	idType := UserIDType(args.Get("id_type", string(OpenID)))
+	switch idType {
+	case OpenID, UnionID, UserID:
+		return idType, nil
+	default:
+		return "", fmt.Errorf("invalid user id type %s", idType)
+	}
+}
+
+// validateUserArgs checks the `id_type` argument.
+func validateUserArgs(args maparser.Args) error {
+	_, err := argsUserIDType(args)
+	return err
+}
+
 type LarkUser struct {
-	Name   string `json:"name"`
-	Email  string `json:"email"`
+	ID        string     `json:"id"`
+	IDType    UserIDType `json:"id_type"`
+	Name      string     `json:"name"`
+	EnName    string     `json:"en_name"`
+	Email     string     `json:"email"`
+	AvatarURL string     `json:"avatar_url"`
+
+	// Deprecated: OpenId holds ID if it is an open id, use ID or OpenID.
 	OpenId string `json:"open_id"`
 }
 
+// OpenID returns the id if it is an open id, or OpenId for users built before ID was added.
+func (u *LarkUser) OpenID() string {
+	return u.idOf(OpenID)
+}
+
+// UnionID returns the id if it is a union id.
+func (u *LarkUser) UnionID() string {
+	return u.idOf(UnionID)
+}
+
+// UserID returns the id if it is a user id.
+func (u *LarkUser) UserID() string {
+	return u.idOf(UserID)
+}
+
+func (u *LarkUser) idOf(idType UserIDType) string {
+	if u.ID == "" && idType == OpenID {
+		return u.OpenId
+	}
+
+	if u.IDType == idType || (u.IDType == "" && idType == OpenID) {
+		return u.ID
+	}
+
+	return ""
+}
+
+// encodeID returns the id written to a person field, OpenId for users built before ID was added.
+func (u *LarkUser) encodeID() string {
+	if u.ID != "" {
+		return u.ID
+	}
+
+	return u.OpenId
+}
+
+// UserIDs returns the ids of users.
+func UserIDs(users []*LarkUser) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		if u != nil {
+			ids = append(ids, u.encodeID())
+		}
+	}
+
+	return ids
+}
+
 func SingleUser(dest reflect.Value, val any) error {
+	return SingleUserArgs(dest, val, nil)
+}
+
+// SingleUserArgs sets a *LarkUser field, the `id_type` argument declares the type of the id.
+func SingleUserArgs(dest reflect.Value, val any, args maparser.Args) error {
 	if val == nil {
 		return nil
 	}
 
-	u, err := ParseUser(val)
+	idType, err := argsUserIDType(args)
 	if err != nil {
 		return err
 	}
 
+	u, err := ParseUserAs(val, idType)
+	if err != nil || u == nil {
+		return err
+	}
+
 	dest.Set(reflect.ValueOf(u))
 	return nil
 }
 
 func MultipleUsers(dest reflect.Value, val any) error {
+	return MultipleUsersArgs(dest, val, nil)
+}
+
+// MultipleUsersArgs sets a []*LarkUser field, the `id_type` argument declares the type of the ids.
+func MultipleUsersArgs(dest reflect.Value, val any, args maparser.Args) error {
 	if val == nil {
 		return nil
 	}
 
-	u, err := ParseMultipleUsers(val)
+	idType, err := argsUserIDType(args)
 	if err != nil {
 		return err
 	}
 
-	dest.Set(reflect.ValueOf(u))
+	u, err := ParseMultipleUsersAs(val, idType)
+	if err != nil {
+		return err
+	}
+
+	maparser.SetValue(dest, u)
 	return nil
 }
 
 func ParseMultipleUsers(val any) ([]*LarkUser, error) {
+	return ParseMultipleUsersAs(val, OpenID)
+}
+
+// ParseMultipleUsersAs parses the users of a person field with ids of idType.
+func ParseMultipleUsersAs(val any, idType UserIDType) ([]*LarkUser, error) {
 	if val == nil {
 		return nil, nil
 	}
 
+	// a created by or modified by field holds a single user object.
+	if _, single := val.(map[string]any); single {
+		u, err := ParseUserAs(val, idType)
+		if err != nil {
+			return nil, err
+		}
+		return []*LarkUser{u}, nil
+	}
+
 	arr, ok := val.([]interface{})
 	if !ok {
 		return nil, fmt.Errorf("ParseMultipleUsers: invalid type %T", val)
 	}
 	var users []*LarkUser
 	for _, v := range arr {
-		u, err := ParseUser(v)
+		u, err := ParseUserAs(v, idType)
 		if err != nil {
 			return nil, err
 		}
@@ -300,46 +368,108 @@ func ParseMultipleUsers(val any) ([]*LarkUser, error) {
 	return users, nil
 }
 
+// ParseUser parses a user object of a person field read with the default open_id user id type.
 func ParseUser(val any) (*LarkUser, error) {
-	if val == nil {
-		return nil, nil
+	return ParseUserAs(val, OpenID)
+}
+
+// ParseUserAs parses a user object with an id of idType, only the id is required,
+// the name and email are absent for some users, e.g. of created by fields.
+func ParseUserAs(val any, idType UserIDType) (*LarkUser, error) {
+	m, err := userMap(val, "ParseUser")
+	if err != nil || m == nil {
+		return nil, err
 	}
 
-	m, ok := val.(map[string]interface{})
+	id, ok := m["id"].(string)
 	if !ok {
-		var arr []interface{}
-		var mm map[string]interface{}
+		return nil, fmt.Errorf("ParseUser: %s", "id field not found")
+	}
 
-		if arr, ok = val.([]interface{}); ok {
-			if mm, ok = arr[0].(map[string]interface{}); ok {
-				m = mm
-			}
-		}
+	user := &LarkUser{
+		ID:        id,
+		IDType:    idType,
+		Name:      mapString(m, "name"),
+		EnName:    mapString(m, "en_name"),
+		Email:     mapString(m, "email"),
+		AvatarURL: mapString(m, "avatar_url"),
+	}
+	if idType == OpenID {
+		user.OpenId = id
+	}
+
+	return user, nil
+}
 
-		if !ok {
-			return nil, fmt.Errorf("ParseUser: invalid type %T", val)
+func encodeUserIds(ids ...string) []map[string]any {
+	users := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			continue
 		}
+		users = append(users, map[string]any{"id": id})
+	}
+
+	if len(users) == 0 {
+		return nil
 	}
 
-	u := &LarkUser{}
-	openId, ok := m["id"]
+	return users
+}
+
+// SingleUserIdEncoder encodes a user id string as a person field value.
+func SingleUserIdEncoder(src reflect.Value) (any, error) {
+	val := maparser.GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	id, ok := val.(string)
 	if !ok {
-		return nil, fmt.Errorf("ParseUser: %s", "id field not found")
+		return nil, fmt.Errorf("SingleUserIdEncoder: invalid type %T", val)
 	}
 
-	u.OpenId = openId.(string)
+	if users := encodeUserIds(id); users != nil {
+		return users, nil
+	}
+
+	return nil, nil
+}
 
-	name, ok := m["name"]
+// SingleUserEncoder encodes a *LarkUser as a person field value.
+func SingleUserEncoder(src reflect.Value) (any, error) {
+	val := maparser.GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	u, ok := val.(LarkUser)
 	if !ok {
-		return nil, fmt.Errorf("ParseUser: %s", "name field not found")
+		return nil, fmt.Errorf("SingleUserEncoder: invalid type %T", val)
+	}
+
+	if users := encodeUserIds(u.encodeID()); users != nil {
+		return users, nil
 	}
-	u.Name = name.(string)
 
-	email, ok := m["email"]
+	return nil, nil
+}
+
+// MultipleUsersEncoder encodes a []*LarkUser as a person field value.
+func MultipleUsersEncoder(src reflect.Value) (any, error) {
+	val := maparser.GetValue(src)
+	if val == nil {
+		return nil, nil
+	}
+
+	arr, ok := val.([]*LarkUser)
 	if !ok {
-		return nil, fmt.Errorf("ParseUser: %s", "email field not found")
+		return nil, fmt.Errorf("MultipleUsersEncoder: invalid type %T", val)
+	}
+
+	if users := encodeUserIds(UserIDs(arr)...); users != nil {
+		return users, nil
 	}
-	u.Email = email.(string)
 
-	return u, nil
+	return nil, nil
 }
@@ -0,0 +1,101 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vbitable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vogo/vlarksdk/maparser"
+)
+
+type UnionUserRecord struct {
+	Owner   *LarkUser   `json:"owner" key:"负责人" parser:"single_user(id_type=union_id)"`
+	Members []*LarkUser `json:"members" key:"成员" parser:"multiple_users(id_type=union_id)"`
+}
+
+type DefaultUserRecord struct {
+	Owner   *LarkUser   `json:"owner" key:"负责人"`
+	Members []*LarkUser `json:"members" key:"成员"`
+}
+
+func TestParseUserIDType(t *testing.T) {
+	fields := loadFixture(t, "user_fields.json")
+
+	record := &UnionUserRecord{}
+	assert.Nil(t, maparser.Parse(record, fields))
+	assert.Equal(t, &LarkUser{
+		ID:        "on_1",
+		IDType:    UnionID,
+		Name:      "张三",
+		EnName:    "vogo",
+		Email:     "vogo@example.com",
+		AvatarURL: "https://example.com/avatar/vogo.png",
+	}, record.Owner)
+	assert.Equal(t, "on_1", record.Owner.UnionID())
+	assert.Equal(t, "", record.Owner.OpenID())
+	assert.Equal(t, "", record.Owner.UserID())
+	assert.Equal(t, []string{"on_1", "on_2"}, UserIDs(record.Members))
+	assert.Equal(t, UnionID, record.Members[1].IDType)
+
+	m, err := maparser.Encode(record)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"负责人": []map[string]any{{"id": "on_1"}},
+		"成员":  []map[string]any{{"id": "on_1"}, {"id": "on_2"}},
+	}, m)
+
+	defaultRecord := &DefaultUserRecord{}
+	assert.Nil(t, maparser.Parse(defaultRecord, fields))
+	assert.Equal(t, OpenID, defaultRecord.Owner.IDType)
+	assert.Equal(t, "on_1", defaultRecord.Owner.OpenID())
+
+	p := maparser.New(maparser.WithParsersFrom(maparser.Default()),
+		maparser.WithParserArgs("single_user", maparser.Args{"id_type": "user_id"}),
+		maparser.WithParserArgs("multiple_users", maparser.Args{"id_type": "user_id"}),
+	)
+	defaultRecord = &DefaultUserRecord{}
+	assert.Nil(t, p.Parse(defaultRecord, fields))
+	assert.Equal(t, "on_1", defaultRecord.Owner.UserID())
+	assert.Equal(t, UserID, defaultRecord.Members[1].IDType)
+
+	// the tag argument overrides the parser default.
+	record = &UnionUserRecord{}
+	assert.Nil(t, p.Parse(record, fields))
+	assert.Equal(t, UnionID, record.Owner.IDType)
+
+	ids, err := ParseMultipleUserUnionIds(fields["成员"])
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"on_1", "on_2"}, ids)
+}
+
+func TestParseUserIDTypeErrors(t *testing.T) {
+	type InvalidRecord struct {
+		Owner *LarkUser `json:"owner" key:"负责人" parser:"single_user(id_type=email)"`
+	}
+	err := maparser.Parse(&InvalidRecord{}, map[string]any{"负责人": []any{map[string]any{"id": "ou_1"}}})
+	assert.ErrorContains(t, err, "invalid user id type email")
+
+	// users built with the deprecated OpenId are still encoded.
+	m, err := maparser.Encode(&DefaultUserRecord{Owner: &LarkUser{OpenId: "ou_1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"负责人": []map[string]any{{"id": "ou_1"}}}, m)
+	assert.Equal(t, "ou_1", (&LarkUser{OpenId: "ou_1"}).OpenID())
+	assert.Equal(t, "", (&LarkUser{OpenId: "ou_1"}).UnionID())
+	assert.Equal(t, "ou_1", (&LarkUser{ID: "ou_1"}).OpenID())
+}